@@ -3,3 +3,5 @@ module github.com/dmars8047/idamlib
 go 1.22.0
 
 require github.com/dmars8047/strval v1.0.1
+
+require golang.org/x/oauth2 v0.26.0
@@ -0,0 +1,131 @@
+package idam
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	AdminApplicationsUrlSuffix = "/api/idam/admin/applications"
+	AdminApplicationUrlSuffix  = "/api/idam/admin/applications/:appId"
+)
+
+// applicationRequest executes a request to an endpoint responding with an Application
+func (client *AdminClient) applicationRequest(ctx context.Context, request apiRequest) (*Application, error) {
+	var application Application
+
+	request.response = &application
+
+	if err := client.executor.execute(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return &application, nil
+}
+
+// CreateApplication method to call the admin create application endpoint
+func (client *AdminClient) CreateApplication(request *ApplicationCreateRequest) (*Application, error) {
+	return client.CreateApplicationContext(context.Background(), request)
+}
+
+// CreateApplicationContext method to call the admin create application endpoint using the provided context
+func (client *AdminClient) CreateApplicationContext(ctx context.Context, request *ApplicationCreateRequest) (*Application, error) {
+	return client.applicationRequest(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      AdminApplicationsUrlSuffix,
+		body:           request,
+		expectedStatus: http.StatusCreated,
+		operation:      "create application",
+	})
+}
+
+// GetApplication method to call the admin get application endpoint
+func (client *AdminClient) GetApplication(appId string) (*Application, error) {
+	return client.GetApplicationContext(context.Background(), appId)
+}
+
+// GetApplicationContext method to call the admin get application endpoint using the provided context
+func (client *AdminClient) GetApplicationContext(ctx context.Context, appId string) (*Application, error) {
+	urlSuffix, err := substitutePathParams(AdminApplicationUrlSuffix, client.executor.appPathParam(appId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.applicationRequest(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusOK,
+		operation:      "get application",
+		retryable:      true,
+	})
+}
+
+// UpdateApplication method to call the admin update application endpoint
+func (client *AdminClient) UpdateApplication(appId string, request *ApplicationUpdateRequest) (*Application, error) {
+	return client.UpdateApplicationContext(context.Background(), appId, request)
+}
+
+// UpdateApplicationContext method to call the admin update application endpoint using the provided context
+func (client *AdminClient) UpdateApplicationContext(ctx context.Context, appId string, request *ApplicationUpdateRequest) (*Application, error) {
+	urlSuffix, err := substitutePathParams(AdminApplicationUrlSuffix, client.executor.appPathParam(appId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.applicationRequest(ctx, apiRequest{
+		method:         http.MethodPatch,
+		urlSuffix:      urlSuffix,
+		body:           request,
+		expectedStatus: http.StatusOK,
+		operation:      "update application",
+	})
+}
+
+// ListApplications method to call the admin list applications endpoint
+func (client *AdminClient) ListApplications(options ApplicationListOptions) (*ApplicationListResponse, error) {
+	return client.ListApplicationsContext(context.Background(), options)
+}
+
+// ListApplicationsContext method to call the admin list applications endpoint using the provided context
+func (client *AdminClient) ListApplicationsContext(ctx context.Context, options ApplicationListOptions) (*ApplicationListResponse, error) {
+	var listResponse ApplicationListResponse
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      withQuery(AdminApplicationsUrlSuffix, options.query()),
+		expectedStatus: http.StatusOK,
+		response:       &listResponse,
+		operation:      "list applications",
+		retryable:      true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &listResponse, nil
+}
+
+// DeleteApplication method to call the admin delete application endpoint
+// The appId is required, the client's default app id is not used for deletes.
+func (client *AdminClient) DeleteApplication(appId string) error {
+	return client.DeleteApplicationContext(context.Background(), appId)
+}
+
+// DeleteApplicationContext method to call the admin delete application endpoint using the provided context
+func (client *AdminClient) DeleteApplicationContext(ctx context.Context, appId string) error {
+	urlSuffix, err := substitutePathParams(AdminApplicationUrlSuffix, pathParam{":appId", appId})
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusNoContent,
+		operation:      "delete application",
+	})
+}
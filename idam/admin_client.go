@@ -0,0 +1,353 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// A client for making http calls to the IDAM service's admin endpoints
+// This client should be used by back-office services managing applications and their users.
+// Requests are authenticated with the service's credentials rather than a user's token.
+type AdminClient struct {
+	executor requestExecutor
+}
+
+const (
+	AdminUsersUrlSuffix        = "/api/idam/admin/applications/:appId/users"
+	AdminUserUrlSuffix         = "/api/idam/admin/applications/:appId/users/:userId"
+	AdminUserDisableUrlSuffix  = "/api/idam/admin/applications/:appId/users/:userId/disable"
+	AdminUserEnableUrlSuffix   = "/api/idam/admin/applications/:appId/users/:userId/enable"
+	AdminUserVerifyUrlSuffix   = "/api/idam/admin/applications/:appId/users/:userId/verify"
+	AdminUserFeaturesUrlSuffix = "/api/idam/admin/applications/:appId/users/:userId/features"
+	AdminUserFeatureUrlSuffix  = "/api/idam/admin/applications/:appId/users/:userId/features/:feature"
+	AdminUserRolesUrlSuffix    = "/api/idam/admin/applications/:appId/users/:userId/roles"
+)
+
+// NewAdminClient creates an AdminClient for the IDAM service at the baseUrl authenticating with the credentials.
+// The same options as New are supported.
+// Usage: NewAdminClient("https://idam.example.com", ServiceCredentials{ClientId: id, ClientSecret: secret})
+func NewAdminClient(baseUrl string, credentials ServiceCredentials, opts ...ClientOption) (*AdminClient, error) {
+	if credentials.ClientId == "" || credentials.ClientSecret == "" {
+		return nil, errors.New("service credentials must have a client id and client secret")
+	}
+
+	base, err := parseBaseUrl(baseUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options, err := applyClientOptions(opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	options.middleware = append(options.middleware, serviceCredentialsMiddleware(credentials))
+
+	return &AdminClient{
+		executor: newRequestExecutor(base, nil, options),
+	}, nil
+}
+
+// serviceCredentialsMiddleware returns Middleware authenticating each request with the credentials using basic auth
+func serviceCredentialsMiddleware(credentials ServiceCredentials) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+			req.SetBasicAuth(credentials.ClientId, credentials.ClientSecret)
+
+			return next.Do(req)
+		})
+	}
+}
+
+// userUrlSuffix substitutes the appId, userId and any other path parameters into the url suffix.
+// ValidationErrors are returned if the userId, another parameter, or both the appId and default app id are empty.
+func (client *AdminClient) userUrlSuffix(urlSuffix string, appId string, userId string, params ...pathParam) (string, error) {
+	params = append([]pathParam{client.executor.appPathParam(appId), {":userId", userId}}, params...)
+	return substitutePathParams(urlSuffix, params...)
+}
+
+// userRequest executes a request to an endpoint responding with a User
+func (client *AdminClient) userRequest(ctx context.Context, request apiRequest) (*User, error) {
+	var user User
+
+	request.response = &user
+
+	if err := client.executor.execute(ctx, request); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// GetUser method to call the admin get user endpoint
+func (client *AdminClient) GetUser(appId string, userId string) (*User, error) {
+	return client.GetUserContext(context.Background(), appId, userId)
+}
+
+// GetUserContext method to call the admin get user endpoint using the provided context
+func (client *AdminClient) GetUserContext(ctx context.Context, appId string, userId string) (*User, error) {
+	urlSuffix, err := client.userUrlSuffix(AdminUserUrlSuffix, appId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusOK,
+		operation:      "get user",
+		retryable:      true,
+	})
+}
+
+// ListUsers method to call the admin list users endpoint
+func (client *AdminClient) ListUsers(appId string, options UserListOptions) (*UserListResponse, error) {
+	return client.ListUsersContext(context.Background(), appId, options)
+}
+
+// ListUsersContext method to call the admin list users endpoint using the provided context
+func (client *AdminClient) ListUsersContext(ctx context.Context, appId string, options UserListOptions) (*UserListResponse, error) {
+	urlSuffix, err := substitutePathParams(AdminUsersUrlSuffix, client.executor.appPathParam(appId))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var listResponse UserListResponse
+
+	err = client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      withQuery(urlSuffix, options.query()),
+		expectedStatus: http.StatusOK,
+		response:       &listResponse,
+		operation:      "list users",
+		retryable:      true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &listResponse, nil
+}
+
+// SearchUsers method to call the admin list users endpoint for users whose username or email contains the search term
+func (client *AdminClient) SearchUsers(appId string, search string, options UserListOptions) (*UserListResponse, error) {
+	return client.SearchUsersContext(context.Background(), appId, search, options)
+}
+
+// SearchUsersContext method to call the admin list users endpoint with a search term using the provided context
+func (client *AdminClient) SearchUsersContext(ctx context.Context, appId string, search string, options UserListOptions) (*UserListResponse, error) {
+	options.Search = search
+	return client.ListUsersContext(ctx, appId, options)
+}
+
+// UpdateUser method to call the admin update user endpoint
+func (client *AdminClient) UpdateUser(appId string, userId string, request *UserUpdateRequest) (*User, error) {
+	return client.UpdateUserContext(context.Background(), appId, userId, request)
+}
+
+// UpdateUserContext method to call the admin update user endpoint using the provided context
+func (client *AdminClient) UpdateUserContext(ctx context.Context, appId string, userId string, request *UserUpdateRequest) (*User, error) {
+	urlSuffix, err := client.userUrlSuffix(AdminUserUrlSuffix, appId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodPatch,
+		urlSuffix:      urlSuffix,
+		body:           request,
+		expectedStatus: http.StatusOK,
+		operation:      "update user",
+	})
+}
+
+// DisableUser method to call the admin disable user endpoint
+// A disabled user cannot log in and their existing tokens are revoked.
+func (client *AdminClient) DisableUser(appId string, userId string) error {
+	return client.DisableUserContext(context.Background(), appId, userId)
+}
+
+// DisableUserContext method to call the admin disable user endpoint using the provided context
+func (client *AdminClient) DisableUserContext(ctx context.Context, appId string, userId string) error {
+	urlSuffix, err := client.userUrlSuffix(AdminUserDisableUrlSuffix, appId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusNoContent,
+		operation:      "disable user",
+	})
+}
+
+// EnableUser method to call the admin enable user endpoint
+func (client *AdminClient) EnableUser(appId string, userId string) error {
+	return client.EnableUserContext(context.Background(), appId, userId)
+}
+
+// EnableUserContext method to call the admin enable user endpoint using the provided context
+func (client *AdminClient) EnableUserContext(ctx context.Context, appId string, userId string) error {
+	urlSuffix, err := client.userUrlSuffix(AdminUserEnableUrlSuffix, appId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusNoContent,
+		operation:      "enable user",
+	})
+}
+
+// DeleteUser method to call the admin delete user endpoint
+// The appId is required, the client's default app id is not used for deletes.
+func (client *AdminClient) DeleteUser(appId string, userId string) error {
+	return client.DeleteUserContext(context.Background(), appId, userId)
+}
+
+// DeleteUserContext method to call the admin delete user endpoint using the provided context
+func (client *AdminClient) DeleteUserContext(ctx context.Context, appId string, userId string) error {
+	urlSuffix, err := substitutePathParams(AdminUserUrlSuffix, pathParam{":appId", appId}, pathParam{":userId", userId})
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusNoContent,
+		operation:      "delete user",
+	})
+}
+
+// ForceVerifyUser method to call the admin verify user endpoint
+// The user is marked as verified without a verification token.
+func (client *AdminClient) ForceVerifyUser(appId string, userId string) error {
+	return client.ForceVerifyUserContext(context.Background(), appId, userId)
+}
+
+// ForceVerifyUserContext method to call the admin verify user endpoint using the provided context
+func (client *AdminClient) ForceVerifyUserContext(ctx context.Context, appId string, userId string) error {
+	urlSuffix, err := client.userUrlSuffix(AdminUserVerifyUrlSuffix, appId, userId)
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusNoContent,
+		operation:      "verify user",
+	})
+}
+
+// SetUserFeatures method to call the admin endpoint replacing a user's features
+func (client *AdminClient) SetUserFeatures(appId string, userId string, features []string) (*User, error) {
+	return client.SetUserFeaturesContext(context.Background(), appId, userId, features)
+}
+
+// SetUserFeaturesContext method to call the admin endpoint replacing a user's features using the provided context
+func (client *AdminClient) SetUserFeaturesContext(ctx context.Context, appId string, userId string, features []string) (*User, error) {
+	if features == nil {
+		features = []string{}
+	}
+
+	urlSuffix, err := client.userUrlSuffix(AdminUserFeaturesUrlSuffix, appId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		body:           &UserFeaturesRequest{Features: features},
+		expectedStatus: http.StatusOK,
+		operation:      "set user features",
+	})
+}
+
+// AddUserFeature method to call the admin endpoint adding a feature to a user
+func (client *AdminClient) AddUserFeature(appId string, userId string, feature string) (*User, error) {
+	return client.AddUserFeatureContext(context.Background(), appId, userId, feature)
+}
+
+// AddUserFeatureContext method to call the admin endpoint adding a feature to a user using the provided context
+func (client *AdminClient) AddUserFeatureContext(ctx context.Context, appId string, userId string, feature string) (*User, error) {
+	urlSuffix, err := client.userUrlSuffix(AdminUserFeatureUrlSuffix, appId, userId, pathParam{":feature", feature})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusOK,
+		operation:      "add user feature",
+	})
+}
+
+// RemoveUserFeature method to call the admin endpoint removing a feature from a user
+func (client *AdminClient) RemoveUserFeature(appId string, userId string, feature string) (*User, error) {
+	return client.RemoveUserFeatureContext(context.Background(), appId, userId, feature)
+}
+
+// RemoveUserFeatureContext method to call the admin endpoint removing a feature from a user using the provided context
+func (client *AdminClient) RemoveUserFeatureContext(ctx context.Context, appId string, userId string, feature string) (*User, error) {
+	urlSuffix, err := client.userUrlSuffix(AdminUserFeatureUrlSuffix, appId, userId, pathParam{":feature", feature})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      urlSuffix,
+		expectedStatus: http.StatusOK,
+		operation:      "remove user feature",
+	})
+}
+
+// SetUserRoles method to call the admin endpoint replacing a user's roles
+// The updated User is returned with the permissions granted by the new roles.
+// Tokens issued before the change keep the old roles and permissions until they are refreshed.
+func (client *AdminClient) SetUserRoles(appId string, userId string, roles []Role) (*User, error) {
+	return client.SetUserRolesContext(context.Background(), appId, userId, roles)
+}
+
+// SetUserRolesContext method to call the admin endpoint replacing a user's roles using the provided context
+func (client *AdminClient) SetUserRolesContext(ctx context.Context, appId string, userId string, roles []Role) (*User, error) {
+	if roles == nil {
+		roles = []Role{}
+	}
+
+	urlSuffix, err := client.userUrlSuffix(AdminUserRolesUrlSuffix, appId, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return client.userRequest(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      urlSuffix,
+		body:           &UserRolesRequest{Roles: roles},
+		expectedStatus: http.StatusOK,
+		operation:      "set user roles",
+	})
+}
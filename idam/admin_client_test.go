@@ -0,0 +1,149 @@
+package idam
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// newTestAdminClient creates an AdminClient for the server configured with the options
+func newTestAdminClient(t *testing.T, server *testServer, opts ...ClientOption) *AdminClient {
+	t.Helper()
+
+	client, err := NewAdminClient(server.URL, ServiceCredentials{ClientId: "service", ClientSecret: "secret"}, opts...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
+
+func TestAdminClientRejectsEmptyPathParams(t *testing.T) {
+	tests := []struct {
+		name      string
+		call      func(client *AdminClient) error
+		wantField string
+	}{
+		{"GetUser without user id", func(client *AdminClient) error {
+			_, err := client.GetUser("app", "")
+			return err
+		}, "userId"},
+		{"DeleteUser without user id", func(client *AdminClient) error {
+			return client.DeleteUser("app", "")
+		}, "userId"},
+		{"DisableUser without app id", func(client *AdminClient) error {
+			return client.DisableUser("", "user-1")
+		}, "appId"},
+		{"RemoveUserFeature without feature", func(client *AdminClient) error {
+			_, err := client.RemoveUserFeature("app", "user-1", "")
+			return err
+		}, "feature"},
+		{"ListUsers without app id", func(client *AdminClient) error {
+			_, err := client.ListUsers("", UserListOptions{})
+			return err
+		}, "appId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusNoContent, ""))
+			client := newTestAdminClient(t, server)
+
+			var validationErrors ValidationErrors
+
+			if err := tt.call(client); !errors.As(err, &validationErrors) || validationErrors[0].Field != tt.wantField {
+				t.Errorf("error = %v, want a validation error of the %s field", err, tt.wantField)
+			}
+
+			if got := server.requestCount(); got != 0 {
+				t.Errorf("requests = %d, want none", got)
+			}
+		})
+	}
+}
+
+func TestAdminClientDeletesRequireExplicitAppId(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(client *AdminClient) error
+	}{
+		{"DeleteApplication", func(client *AdminClient) error {
+			return client.DeleteApplication("")
+		}},
+		{"DeleteUser", func(client *AdminClient) error {
+			return client.DeleteUser("", "user-1")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusNoContent, ""))
+			client := newTestAdminClient(t, server, WithDefaultAppID("default-app"))
+
+			var validationErrors ValidationErrors
+
+			if err := tt.call(client); !errors.As(err, &validationErrors) || validationErrors[0].Field != "appId" {
+				t.Errorf("error = %v, want a validation error of the appId field", err)
+			}
+
+			if got := server.requestCount(); got != 0 {
+				t.Errorf("requests = %d, want none", got)
+			}
+		})
+	}
+}
+
+func TestAdminClientListingsEncodePaging(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	client := newTestAdminClient(t, server)
+
+	if _, err := client.ListApplications(ApplicationListOptions{Page: 2, PageSize: 50}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "page=2&page_size=50"; server.lastRequest(t).rawQuery != want {
+		t.Errorf("ListApplications query = %q, want %q", server.lastRequest(t).rawQuery, want)
+	}
+
+	if _, err := client.SearchUsers("app", "alice", UserListOptions{Page: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "page=3&search=alice"; server.lastRequest(t).rawQuery != want {
+		t.Errorf("SearchUsers query = %q, want %q", server.lastRequest(t).rawQuery, want)
+	}
+}
+
+func TestAdminClientAuthenticatesWithServiceCredentials(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{"id":"user-1","username":"alice"}`))
+	client := newTestAdminClient(t, server)
+
+	user, err := client.GetUser("app", "user-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Id != "user-1" {
+		t.Errorf("user id = %q, want %q", user.Id, "user-1")
+	}
+
+	request := server.lastRequest(t)
+
+	if want := "/api/idam/admin/applications/app/users/user-1"; request.method != http.MethodGet || request.path != want {
+		t.Errorf("request = %s %s, want %s %s", request.method, request.path, http.MethodGet, want)
+	}
+
+	if clientId, clientSecret, ok := (&http.Request{Header: request.header}).BasicAuth(); !ok || clientId != "service" || clientSecret != "secret" {
+		t.Errorf("basic auth = %q:%q, want the service credentials", clientId, clientSecret)
+	}
+}
+
+func TestNewAdminClientRequiresCredentials(t *testing.T) {
+	for _, credentials := range []ServiceCredentials{{}, {ClientId: "service"}, {ClientSecret: "secret"}} {
+		if _, err := NewAdminClient("https://idam.example.com", credentials); err == nil {
+			t.Errorf("NewAdminClient with credentials %+v returned no error", credentials)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package idam
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/dmars8047/strval"
+)
+
+// Application is an application registered with the IDAM service.
+// Users register and log in to a specific application.
+type Application struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	// The redirect uris users may be returned to after authenticating
+	AllowedRedirectURIs []string `json:"allowed_redirect_uris"`
+	// The lifetime of access tokens issued for the application, in seconds
+	AccessTokenLifetime int64 `json:"access_token_lifetime"`
+	// The lifetime of refresh tokens issued for the application, in seconds
+	RefreshTokenLifetime int64          `json:"refresh_token_lifetime"`
+	PasswordPolicy       PasswordPolicy `json:"password_policy"`
+	// The features enabled for the application
+	Features     []string  `json:"features"`
+	CreatedAtUTC time.Time `json:"created_at_utc"`
+}
+
+// ApplicationCreateRequest is the request object for the admin create application endpoint
+type ApplicationCreateRequest struct {
+	Name                string   `json:"name"`
+	AllowedRedirectURIs []string `json:"allowed_redirect_uris"`
+	// The lifetime of access tokens in seconds, the service default is used if zero
+	AccessTokenLifetime int64 `json:"access_token_lifetime,omitempty"`
+	// The lifetime of refresh tokens in seconds, the service default is used if zero
+	RefreshTokenLifetime int64 `json:"refresh_token_lifetime,omitempty"`
+	// The password policy, the service default is used if nil
+	PasswordPolicy *PasswordPolicy `json:"password_policy,omitempty"`
+	Features       []string        `json:"features"`
+}
+
+// Validate validates the create application request
+func (request *ApplicationCreateRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the create application request, returning the errors of each field
+func (request *ApplicationCreateRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateApplicationName(request.Name)...)
+	validationErrors = append(validationErrors, validateRedirectURIs(request.AllowedRedirectURIs)...)
+	validationErrors = append(validationErrors, validateTokenLifetimes(request.AccessTokenLifetime, request.RefreshTokenLifetime)...)
+
+	return validationErrors
+}
+
+// ApplicationUpdateRequest is the request object for the admin update application endpoint
+// Only the fields that are set are updated.
+type ApplicationUpdateRequest struct {
+	Name                 *string         `json:"name,omitempty"`
+	AllowedRedirectURIs  *[]string       `json:"allowed_redirect_uris,omitempty"`
+	AccessTokenLifetime  *int64          `json:"access_token_lifetime,omitempty"`
+	RefreshTokenLifetime *int64          `json:"refresh_token_lifetime,omitempty"`
+	PasswordPolicy       *PasswordPolicy `json:"password_policy,omitempty"`
+	Features             *[]string       `json:"features,omitempty"`
+}
+
+// Validate validates the update application request
+func (request *ApplicationUpdateRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the update application request, returning the errors of each field
+func (request *ApplicationUpdateRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	if request.Name != nil {
+		validationErrors = append(validationErrors, validateApplicationName(*request.Name)...)
+	}
+
+	if request.AllowedRedirectURIs != nil {
+		validationErrors = append(validationErrors, validateRedirectURIs(*request.AllowedRedirectURIs)...)
+	}
+
+	var accessTokenLifetime, refreshTokenLifetime int64
+
+	if request.AccessTokenLifetime != nil {
+		accessTokenLifetime = *request.AccessTokenLifetime
+	}
+
+	if request.RefreshTokenLifetime != nil {
+		refreshTokenLifetime = *request.RefreshTokenLifetime
+	}
+
+	validationErrors = append(validationErrors, validateTokenLifetimes(accessTokenLifetime, refreshTokenLifetime)...)
+
+	return validationErrors
+}
+
+// ApplicationListOptions controls the paging of an application listing
+type ApplicationListOptions struct {
+	// The 1-based page to return, the first page is returned if zero
+	Page int
+	// The number of applications per page, the service default is used if zero
+	PageSize int
+}
+
+// query encodes the options as url query parameters
+func (options ApplicationListOptions) query() url.Values {
+	return pageQuery(options.Page, options.PageSize)
+}
+
+// ApplicationListResponse is a page of applications returned by the admin application listing endpoint
+type ApplicationListResponse struct {
+	Applications []Application `json:"applications"`
+	Page         int           `json:"page"`
+	PageSize     int           `json:"page_size"`
+	TotalCount   int           `json:"total_count"`
+}
+
+// HasNextPage reports whether there are more applications after this page
+func (response *ApplicationListResponse) HasNextPage() bool {
+	return response.Page*response.PageSize < response.TotalCount
+}
+
+// validateApplicationName validates an application name, returning the validation errors if it is invalid
+func validateApplicationName(name string) ValidationErrors {
+	// The name must not be empty and have a max length of 100 characters
+	return validateField(name, "name",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+		rule(ValidationRuleMaxLength, strval.MustHaveMaxLengthOf(100)).with("length", "100"),
+		rule(ValidationRulePrintable, strval.MustOnlyContainPrintableCharacters()))
+}
+
+// validateRedirectURIs validates that each redirect uri is an absolute url without a fragment
+func validateRedirectURIs(redirectURIs []string) ValidationErrors {
+	var validationErrors ValidationErrors
+
+	for _, redirectURI := range redirectURIs {
+		parsed, err := url.Parse(redirectURI)
+
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" || parsed.Fragment != "" {
+			validationErrors = append(validationErrors, ValidationError{
+				Field:   "allowed_redirect_uris",
+				Rule:    ValidationRuleAbsoluteURL,
+				Message: fmt.Sprintf("allowed_redirect_uris must only contain absolute urls without a fragment: %s", redirectURI),
+				Params:  map[string]string{"value": redirectURI},
+			})
+		}
+	}
+
+	return validationErrors
+}
+
+// validateTokenLifetimes validates the token lifetimes, zero values are not checked
+func validateTokenLifetimes(accessTokenLifetime int64, refreshTokenLifetime int64) ValidationErrors {
+	var validationErrors ValidationErrors
+
+	if accessTokenLifetime < 0 {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   "access_token_lifetime",
+			Rule:    ValidationRuleNonNegative,
+			Message: "access_token_lifetime must not be negative",
+		})
+	}
+
+	if refreshTokenLifetime < 0 {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   "refresh_token_lifetime",
+			Rule:    ValidationRuleNonNegative,
+			Message: "refresh_token_lifetime must not be negative",
+		})
+	}
+
+	if accessTokenLifetime > 0 && refreshTokenLifetime > 0 && refreshTokenLifetime < accessTokenLifetime {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   "refresh_token_lifetime",
+			Rule:    ValidationRuleNotShorterThan,
+			Message: "refresh_token_lifetime must not be shorter than access_token_lifetime",
+			Params:  map[string]string{"other": "access_token_lifetime"},
+		})
+	}
+
+	return validationErrors
+}
@@ -0,0 +1,156 @@
+package idam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// TokenValidator validates an authorization token and resolves the User it was issued to.
+// *UserAuthClient satisfies this interface.
+type TokenValidator interface {
+	ValidateTokenContext(ctx context.Context, authToken string) (*User, error)
+}
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	retryableContextKey
+	localizerContextKey
+)
+
+// MiddlewareOption configures the middleware created by Authenticate
+type MiddlewareOption func(*middlewareOptions)
+
+// middlewareOptions is the configuration assembled from the MiddlewareOptions passed to Authenticate
+type middlewareOptions struct {
+	localizer Localizer
+}
+
+// WithLocalizer renders the error responses written by Authenticate in the request's Accept-Language.
+// RequirePermission and RequireRole used inside Authenticate render their error responses with the same localizer.
+// Usage: idam.Authenticate(client, idam.WithLocalizer(idam.DefaultLocalizer()))
+func WithLocalizer(localizer Localizer) MiddlewareOption {
+	return func(options *middlewareOptions) {
+		options.localizer = localizer
+	}
+}
+
+// Authenticate returns net/http middleware that validates the request's bearer token with the validator.
+// On success the resolved User is stored in the request context and can be read with UserFromContext.
+// On failure an ErrorResponse JSON body is written and the next handler is not called.
+// Usage: mux.Handle("/orders", idam.Authenticate(client)(ordersHandler))
+func Authenticate(validator TokenValidator, opts ...MiddlewareOption) func(http.Handler) http.Handler {
+	var options middlewareOptions
+
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if options.localizer != nil {
+				r = r.WithContext(context.WithValue(r.Context(), localizerContextKey, options.localizer))
+			}
+
+			authToken := BearerTokenFromRequest(r)
+
+			if authToken == "" {
+				writeLocalizedErrorResponse(w, r, http.StatusUnauthorized, NewErrorResponse(InvalidRequestHeaders, InvalidRequestHeadersMessage))
+				return
+			}
+
+			user, err := validator.ValidateTokenContext(r.Context(), authToken)
+
+			if err != nil {
+				writeAuthError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), user)))
+		})
+	}
+}
+
+// writeAuthError writes the error of a failed authentication or authorization check.
+// Only authentication and authorization errors are passed on to the caller. Failures to reach IDAM are written
+// as a 503 and any other error reported by IDAM (e.g. ApplicationNotFound) as a 500, both with an UnhandledError,
+// so the caller learns nothing about the service's configuration.
+func writeAuthError(w http.ResponseWriter, r *http.Request, err error) {
+	errorResponse, ok := AsErrorResponse(err)
+
+	if !ok {
+		writeLocalizedErrorResponse(w, r, http.StatusServiceUnavailable, NewUnhandledErrorResponse())
+		return
+	}
+
+	switch errorResponse.Code {
+	case InvalidAuthToken, AuthTokenExpired, InvalidRequestHeaders:
+		writeLocalizedErrorResponse(w, r, http.StatusUnauthorized, errorResponse)
+	case UserNotFound:
+		// The token was issued to a user that no longer exists
+		writeLocalizedErrorResponse(w, r, http.StatusUnauthorized, NewErrorResponse(InvalidAuthToken, InvalidAuthTokenMessage))
+	case AccessDenied, UserNotVerified, UserAccountLockout:
+		writeLocalizedErrorResponse(w, r, http.StatusForbidden, errorResponse)
+	default:
+		writeLocalizedErrorResponse(w, r, http.StatusInternalServerError, NewUnhandledErrorResponse())
+	}
+}
+
+// writeLocalizedErrorResponse writes the ErrorResponse in the request's language if Authenticate was given a Localizer
+func writeLocalizedErrorResponse(w http.ResponseWriter, r *http.Request, statusCode int, errorResponse *ErrorResponse) {
+	if localizer, ok := r.Context().Value(localizerContextKey).(Localizer); ok {
+		errorResponse = LocalizeErrorResponse(localizer, errorResponse, RequestLanguages(r)...)
+	}
+
+	WriteErrorResponse(w, statusCode, errorResponse)
+}
+
+// BearerTokenFromRequest returns the token from the request's Authorization header with any "Bearer " prefix removed.
+// The scheme is matched case insensitively. An empty string is returned if the header is missing or uses another scheme.
+func BearerTokenFromRequest(r *http.Request) string {
+	header := strings.TrimSpace(r.Header.Get("Authorization"))
+
+	if token, ok := cutBearerScheme(header); ok {
+		return token
+	}
+
+	// A header with another scheme, e.g. Basic credentials, carries no bearer token
+	if strings.Contains(header, " ") {
+		return ""
+	}
+
+	return header
+}
+
+// cutBearerScheme returns the token of an Authorization header value using the Bearer scheme.
+// Auth schemes are case insensitive (RFC 9110), so "bearer" and "BEARER" are accepted too.
+func cutBearerScheme(value string) (token string, ok bool) {
+	scheme, token, found := strings.Cut(strings.TrimSpace(value), " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	return strings.TrimSpace(token), true
+}
+
+// ContextWithUser returns a copy of ctx carrying the authenticated user
+func ContextWithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated user stored in the context by Authenticate
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(userContextKey).(*User)
+	return user, ok && user != nil
+}
+
+// WriteErrorResponse writes the ErrorResponse as a JSON body with the given status code
+func WriteErrorResponse(w http.ResponseWriter, statusCode int, errorResponse *ErrorResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(errorResponse)
+}
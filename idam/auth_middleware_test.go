@@ -0,0 +1,87 @@
+package idam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// tokenValidatorFunc adapts a function to a TokenValidator
+type tokenValidatorFunc func(ctx context.Context, authToken string) (*User, error)
+
+func (f tokenValidatorFunc) ValidateTokenContext(ctx context.Context, authToken string) (*User, error) {
+	return f(ctx, authToken)
+}
+
+func TestBearerTokenFromRequest(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"Bearer token-1", "token-1"},
+		{"bearer token-1", "token-1"},
+		{"BEARER  token-1 ", "token-1"},
+		{"token-1", "token-1"},
+		{"Basic dXNlcjpwYXNz", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "http://app.example.com", nil)
+			r.Header.Set("Authorization", tt.header)
+
+			if got := BearerTokenFromRequest(r); got != tt.want {
+				t.Errorf("BearerTokenFromRequest = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthenticateErrorMapping(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		{"invalid token", ErrInvalidAuthToken, http.StatusUnauthorized, InvalidAuthToken},
+		{"expired token", ErrAuthTokenExpired, http.StatusUnauthorized, AuthTokenExpired},
+		{"deleted user", ErrUserNotFound, http.StatusUnauthorized, InvalidAuthToken},
+		{"unverified user", ErrUserNotVerified, http.StatusForbidden, UserNotVerified},
+		{"misconfigured application", ErrApplicationNotFound, http.StatusInternalServerError, UnhandledError},
+		{"validation failure", ErrRequestValidationFailure, http.StatusInternalServerError, UnhandledError},
+		{"idam unreachable", errors.New("connection refused"), http.StatusServiceUnavailable, UnhandledError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validator := tokenValidatorFunc(func(ctx context.Context, authToken string) (*User, error) {
+				return nil, tt.err
+			})
+
+			handler := Authenticate(validator)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				t.Error("next handler called for a rejected token")
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			r.Header.Set("Authorization", "Bearer token-1")
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, r)
+
+			var errorResponse ErrorResponse
+
+			if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+				t.Fatal(err)
+			}
+
+			if w.Code != tt.wantStatus || errorResponse.Code != tt.wantCode {
+				t.Errorf("response = %d %v, want %d %v", w.Code, errorResponse.Code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package idam_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmars8047/idamlib/idam"
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+func TestChangePassword(t *testing.T) {
+	server := newFakeServer(t)
+	login := loginUser(t, server, "alice", "alice@example.com")
+	client := server.UserAuthClient()
+
+	const newPassword = "N3w!Passw0rd"
+
+	tests := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		want            error
+	}{
+		{"wrong current password", "Wr0ng!Password", newPassword, idam.ErrInvalidCredentials},
+		{"weak new password", testPassword, "weak", idam.ErrRequestValidationFailure},
+		{"unchanged password", testPassword, testPassword, idam.ErrRequestValidationFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := client.ChangePassword(login.Token, tt.currentPassword, tt.newPassword); !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := client.ChangePassword(login.Token, testPassword, newPassword); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := client.Login(idamtest.DefaultApplicationId, &idam.UserLoginRequest{Email: "alice@example.com", Password: testPassword}); !errors.Is(err, idam.ErrInvalidCredentials) {
+		t.Errorf("Login with the old password error = %v, want %v", err, idam.ErrInvalidCredentials)
+	}
+
+	if _, err := client.Login(idamtest.DefaultApplicationId, &idam.UserLoginRequest{Email: "alice@example.com", Password: newPassword}); err != nil {
+		t.Errorf("Login with the new password returned error %v", err)
+	}
+}
+
+func TestChangePasswordRequiresAToken(t *testing.T) {
+	client := newFakeServer(t).UserAuthClient()
+
+	if err := client.ChangePassword("not-a-token", testPassword, "N3w!Passw0rd"); !errors.Is(err, idam.ErrInvalidAuthToken) {
+		t.Errorf("error = %v, want %v", err, idam.ErrInvalidAuthToken)
+	}
+}
@@ -0,0 +1,155 @@
+package idam
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// ClientOption configures a client created with New
+type ClientOption func(*clientOptions) error
+
+// clientOptions is the configuration assembled from the ClientOptions passed to a constructor
+type clientOptions struct {
+	httpClient   *http.Client
+	timeout      time.Duration
+	userAgent    string
+	defaultAppId string
+	headers      http.Header
+	middleware   []Middleware
+	metadata     *ProviderMetadata
+}
+
+// WithHTTPClient sets the http client used to send requests, http.DefaultClient is used by default
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(options *clientOptions) error {
+		if httpClient == nil {
+			return errors.New("http client must not be nil")
+		}
+
+		options.httpClient = httpClient
+
+		return nil
+	}
+}
+
+// WithTimeout sets the time limit for each request made by the client.
+// The http client is copied so a client shared with other code is not modified.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(options *clientOptions) error {
+		if timeout <= 0 {
+			return errors.New("timeout must be positive")
+		}
+
+		options.timeout = timeout
+
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request
+func WithUserAgent(userAgent string) ClientOption {
+	return func(options *clientOptions) error {
+		options.userAgent = userAgent
+		return nil
+	}
+}
+
+// WithDefaultAppID sets the application id used when a method is called with an empty appId.
+// AdminClient deletes never use it and require an explicit appId.
+func WithDefaultAppID(appId string) ClientOption {
+	return func(options *clientOptions) error {
+		options.defaultAppId = appId
+		return nil
+	}
+}
+
+// WithHeaders adds default headers sent with each request, unless the call sets the header itself. It may be used more than once.
+func WithHeaders(headers http.Header) ClientOption {
+	return func(options *clientOptions) error {
+		if options.headers == nil {
+			options.headers = http.Header{}
+		}
+
+		for key, values := range headers {
+			for _, value := range values {
+				options.headers.Add(key, value)
+			}
+		}
+
+		return nil
+	}
+}
+
+// WithMiddleware adds middleware every request is sent through. It may be used more than once.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(options *clientOptions) error {
+		options.middleware = append(options.middleware, middleware...)
+		return nil
+	}
+}
+
+// WithRetryPolicy retries transient failures according to the policy, see RetryMiddleware
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(options *clientOptions) error {
+		if policy.MaxAttempts < 1 {
+			return errors.New("retry policy must allow at least one attempt")
+		}
+
+		options.middleware = append(options.middleware, RetryMiddleware(policy))
+
+		return nil
+	}
+}
+
+// applyClientOptions applies the options in order, returning the first error encountered
+func applyClientOptions(opts []ClientOption) (clientOptions, error) {
+	var options clientOptions
+
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return clientOptions{}, err
+		}
+	}
+
+	return options, nil
+}
+
+// HeadersMiddleware returns Middleware adding the headers to each request as defaults.
+// A header the request already has, such as the Authorization or Content-Type set for the call, is left unchanged.
+func HeadersMiddleware(headers http.Header) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			req = req.Clone(req.Context())
+
+			for key, values := range headers {
+				if len(req.Header.Values(key)) > 0 {
+					continue
+				}
+
+				for _, value := range values {
+					req.Header.Add(key, value)
+				}
+			}
+
+			return next.Do(req)
+		})
+	}
+}
+
+// parseBaseUrl parses the base url of the IDAM service, which must be an absolute http or https url
+func parseBaseUrl(baseUrl string) (*url.URL, error) {
+	base, err := url.Parse(baseUrl)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid idam base url - %v", err)
+	}
+
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("invalid idam base url %q - must be an absolute http or https url", baseUrl)
+	}
+
+	return base, nil
+}
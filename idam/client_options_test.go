@@ -0,0 +1,111 @@
+package idam
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewRejectsInvalidConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseUrl string
+		opts    []ClientOption
+	}{
+		{"empty base url", "", nil},
+		{"relative base url", "idam.example.com", nil},
+		{"base url with another scheme", "ftp://idam.example.com", nil},
+		{"nil http client", "https://idam.example.com", []ClientOption{WithHTTPClient(nil)}},
+		{"zero timeout", "https://idam.example.com", []ClientOption{WithTimeout(0)}},
+		{"retry policy without attempts", "https://idam.example.com", []ClientOption{WithRetryPolicy(RetryPolicy{})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.baseUrl, tt.opts...); err == nil {
+				t.Error("New returned no error")
+			}
+		})
+	}
+}
+
+func TestClientOptions(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	client := newTestClient(t, server, WithUserAgent("my-app/1.0"), WithDefaultAppID("default-app"))
+
+	if _, err := client.Login("", &UserLoginRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	request := server.lastRequest(t)
+
+	if want := "/api/idam/user-account/applications/default-app/login"; request.path != want {
+		t.Errorf("path = %q, want the default app id substituted, %q", request.path, want)
+	}
+
+	if got := request.header.Get("User-Agent"); got != "my-app/1.0" {
+		t.Errorf("User-Agent = %q, want %q", got, "my-app/1.0")
+	}
+
+	if _, err := client.Login("other-app", &UserLoginRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "/api/idam/user-account/applications/other-app/login"; server.lastRequest(t).path != want {
+		t.Errorf("path = %q, want the app id of the call, %q", server.lastRequest(t).path, want)
+	}
+}
+
+func TestWithTimeoutDoesNotModifyTheHTTPClient(t *testing.T) {
+	release := make(chan struct{})
+
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+
+	// Registered after the server's cleanup so the handler is released before the server is closed
+	t.Cleanup(func() { close(release) })
+
+	httpClient := &http.Client{}
+	client := newTestClient(t, server, WithHTTPClient(httpClient), WithTimeout(10*time.Millisecond))
+
+	if _, err := client.GetCurrentUser("token"); !IsTransportError(err) {
+		t.Errorf("error = %v, want the timeout of the request", err)
+	}
+
+	if httpClient.Timeout != 0 {
+		t.Errorf("http client timeout = %v, want the caller's client unchanged", httpClient.Timeout)
+	}
+}
+
+func TestWithHeadersDoesNotOverrideHeadersOfTheCall(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+
+	client := newTestClient(t, server, WithHeaders(http.Header{
+		"Authorization": {"Bearer default"},
+		"Content-Type":  {"text/plain"},
+		"X-Tenant":      {"tenant-1"},
+	}))
+
+	if _, err := client.RefreshOAuth2Token("app", "refresh-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	header := server.lastRequest(t).header
+
+	if got := header.Get("Content-Type"); got != "application/x-www-form-urlencoded" {
+		t.Errorf("Content-Type = %q, want the form content type of the call", got)
+	}
+
+	if got := header.Get("X-Tenant"); got != "tenant-1" {
+		t.Errorf("X-Tenant = %q, want the default header", got)
+	}
+
+	if _, err := client.GetCurrentUser("token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := server.lastRequest(t).header.Values("Authorization"); len(got) != 1 || got[0] != "Bearer token" {
+		t.Errorf("Authorization = %q, want the token of the call", got)
+	}
+}
@@ -1,6 +1,6 @@
 package idam
 
-// Password requirements
+// Password requirements of the DefaultPasswordPolicy
 const (
 	// Minimum password length
 	MinPasswordLength = 8
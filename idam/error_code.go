@@ -0,0 +1,79 @@
+package idam
+
+import "strconv"
+
+// ErrorCode identifies the kind of error reported by the IDAM API in an ErrorResponse
+type ErrorCode uint16
+
+// errorCodeNames maps each error code to its name and default message
+var errorCodeNames = map[ErrorCode]struct {
+	name    string
+	message string
+}{
+	UnhandledError:                       {"UnhandledError", UnhandledErrorMessage},
+	RequestPayloadInvalid:                {"RequestPayloadInvalid", RequestBodyInvalidMessage},
+	RequestValidationFailure:             {"RequestValidationFailure", RequestValidationFailureMessage},
+	ApplicationNotFound:                  {"ApplicationNotFound", ApplicationNotFoundMessage},
+	InvalidCredentials:                   {"InvalidCredentials", InvalidCredentialsMessage},
+	DataConflict:                         {"DataConflict", DataConflictMessage},
+	UserNotVerified:                      {"UserNotVerified", UserNotVerifiedMessage},
+	InvalidAuthToken:                     {"InvalidAuthToken", InvalidAuthTokenMessage},
+	AccessDenied:                         {"AccessDenied", AccessDeniedMessage},
+	InvalidUserVerficationToken:          {"InvalidUserVerficationToken", InvalidUserVerficationTokenMessage},
+	UserNotFound:                         {"UserNotFound", UserNotFoundMessage},
+	InvalidPasswordResetToken:            {"InvalidPasswordResetToken", InvalidPasswordResetTokenMessage},
+	InvalidPasswordResetVerificationCode: {"InvalidPasswordResetVerificationCode", InvalidPasswordResetVerificationCodeMessage},
+	InvalidRequestHeaders:                {"InvalidRequestHeaders", InvalidRequestHeadersMessage},
+	AuthTokenExpired:                     {"AuthTokenExpired", AuthTokenExpiredMessage},
+	UserAccountLockout:                   {"UserAccountLockout", UserAccountLockoutMessage},
+	InvalidMFACode:                       {"InvalidMFACode", InvalidMFACodeMessage},
+	InvalidMFAChallenge:                  {"InvalidMFAChallenge", InvalidMFAChallengeMessage},
+	ExternalProviderNotFound:             {"ExternalProviderNotFound", ExternalProviderNotFoundMessage},
+	InvalidExternalAuthorization:         {"InvalidExternalAuthorization", InvalidExternalAuthorizationMessage},
+	SessionNotFound:                      {"SessionNotFound", SessionNotFoundMessage},
+}
+
+// String returns the name of the error code, e.g. "InvalidCredentials"
+func (code ErrorCode) String() string {
+	if entry, ok := errorCodeNames[code]; ok {
+		return entry.name
+	}
+
+	return "ErrorCode(" + strconv.Itoa(int(code)) + ")"
+}
+
+// Message returns the default message for the error code
+func (code ErrorCode) Message() string {
+	if entry, ok := errorCodeNames[code]; ok {
+		return entry.message
+	}
+
+	return UnhandledErrorMessage
+}
+
+// Sentinel errors for each error code. Errors returned by the clients match these with errors.Is.
+// Usage: if errors.Is(err, idam.ErrInvalidCredentials) { ... }
+// The sentinels must not be modified.
+var (
+	ErrUnhandledError                       = NewErrorResponse(UnhandledError, UnhandledErrorMessage)
+	ErrRequestPayloadInvalid                = NewErrorResponse(RequestPayloadInvalid, RequestBodyInvalidMessage)
+	ErrRequestValidationFailure             = NewErrorResponse(RequestValidationFailure, RequestValidationFailureMessage)
+	ErrApplicationNotFound                  = NewErrorResponse(ApplicationNotFound, ApplicationNotFoundMessage)
+	ErrInvalidCredentials                   = NewErrorResponse(InvalidCredentials, InvalidCredentialsMessage)
+	ErrDataConflict                         = NewErrorResponse(DataConflict, DataConflictMessage)
+	ErrUserNotVerified                      = NewErrorResponse(UserNotVerified, UserNotVerifiedMessage)
+	ErrInvalidAuthToken                     = NewErrorResponse(InvalidAuthToken, InvalidAuthTokenMessage)
+	ErrAccessDenied                         = NewErrorResponse(AccessDenied, AccessDeniedMessage)
+	ErrInvalidUserVerficationToken          = NewErrorResponse(InvalidUserVerficationToken, InvalidUserVerficationTokenMessage)
+	ErrUserNotFound                         = NewErrorResponse(UserNotFound, UserNotFoundMessage)
+	ErrInvalidPasswordResetToken            = NewErrorResponse(InvalidPasswordResetToken, InvalidPasswordResetTokenMessage)
+	ErrInvalidPasswordResetVerificationCode = NewErrorResponse(InvalidPasswordResetVerificationCode, InvalidPasswordResetVerificationCodeMessage)
+	ErrInvalidRequestHeaders                = NewErrorResponse(InvalidRequestHeaders, InvalidRequestHeadersMessage)
+	ErrAuthTokenExpired                     = NewErrorResponse(AuthTokenExpired, AuthTokenExpiredMessage)
+	ErrUserAccountLockout                   = NewErrorResponse(UserAccountLockout, UserAccountLockoutMessage)
+	ErrInvalidMFACode                       = NewErrorResponse(InvalidMFACode, InvalidMFACodeMessage)
+	ErrInvalidMFAChallenge                  = NewErrorResponse(InvalidMFAChallenge, InvalidMFAChallengeMessage)
+	ErrExternalProviderNotFound             = NewErrorResponse(ExternalProviderNotFound, ExternalProviderNotFoundMessage)
+	ErrInvalidExternalAuthorization         = NewErrorResponse(InvalidExternalAuthorization, InvalidExternalAuthorizationMessage)
+	ErrSessionNotFound                      = NewErrorResponse(SessionNotFound, SessionNotFoundMessage)
+)
@@ -1,11 +1,23 @@
 package idam
 
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+)
+
 // ErrorResponse is the response returned when an error
 // is encoutered during the processing of a request to the IDAM API
 type ErrorResponse struct {
-	Code    uint16   `json:"error_code"`
-	Message string   `json:"error_message"`
-	Details []string `json:"error_details"`
+	Code    ErrorCode `json:"error_code"`
+	Message string    `json:"error_message"`
+	Details []string  `json:"error_details"`
+	// The field level errors of a RequestValidationFailure
+	ValidationErrors ValidationErrors `json:"validation_errors,omitempty"`
+	// The http status code the ErrorResponse was received with, set on errors returned by the clients
+	HTTPStatus int `json:"-"`
 }
 
 // Error returns the error message for the ErrorResponse
@@ -13,9 +25,50 @@ func (err ErrorResponse) Error() string {
 	return err.Message
 }
 
+// Is reports whether the target is an ErrorResponse with the same error code.
+// This allows errors returned by the clients to be matched against the sentinel errors with errors.Is.
+// Usage: errors.Is(err, idam.ErrInvalidCredentials)
+func (err ErrorResponse) Is(target error) bool {
+	switch t := target.(type) {
+	case *ErrorResponse:
+		return t != nil && t.Code == err.Code
+	case ErrorResponse:
+		return t.Code == err.Code
+	default:
+		return false
+	}
+}
+
+// AsErrorResponse returns the ErrorResponse reported by the IDAM service if err is, or wraps, one.
+// A false result means the error was not reported by IDAM, IsTransportError tells whether it is a transport failure.
+func AsErrorResponse(err error) (*ErrorResponse, bool) {
+	var errorResponse *ErrorResponse
+
+	if errors.As(err, &errorResponse) {
+		return errorResponse, true
+	}
+
+	return nil, false
+}
+
+// IsTransportError reports whether err is a failure to communicate with the IDAM service,
+// either a *TransportError for a response that could not be understood or a network error such as a refused connection.
+// Errors reported by IDAM in an ErrorResponse, ValidationErrors and cancellation by the caller are not transport errors.
+func IsTransportError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var transportErr *TransportError
+	var urlErr *url.Error
+	var netErr net.Error
+
+	return errors.As(err, &transportErr) || errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
 // NewErrorResponse creates an ErrorResponse with the given code and message.
 // Usage: NewErrorResponse(0, "An error occured during validation")
-func NewErrorResponse(code uint16, message string) *ErrorResponse {
+func NewErrorResponse(code ErrorCode, message string) *ErrorResponse {
 	return &ErrorResponse{
 		Code:    code,
 		Message: message,
@@ -25,7 +78,7 @@ func NewErrorResponse(code uint16, message string) *ErrorResponse {
 
 // NewDetailedErrorResponse creates an ErrorResponse with the given code, message and details.
 // Usage: NewDetailedErrorResponse(0, "An error occured during validation","message1", "message2")
-func NewDetailedErrorResponse(code uint16, message string, details ...string) *ErrorResponse {
+func NewDetailedErrorResponse(code ErrorCode, message string, details ...string) *ErrorResponse {
 	return &ErrorResponse{
 		Code:    code,
 		Message: message,
@@ -42,57 +95,94 @@ func NewUnhandledErrorResponse() *ErrorResponse {
 	}
 }
 
+// HTTPStatusForErrorCode returns the http status code the IDAM service responds with for the given error code
+func HTTPStatusForErrorCode(code ErrorCode) int {
+	switch code {
+	case RequestPayloadInvalid, RequestValidationFailure, InvalidUserVerficationToken,
+		InvalidPasswordResetToken, InvalidPasswordResetVerificationCode, InvalidRequestHeaders, InvalidExternalAuthorization:
+		return http.StatusBadRequest
+	case InvalidCredentials, InvalidAuthToken, AuthTokenExpired, InvalidMFACode, InvalidMFAChallenge:
+		return http.StatusUnauthorized
+	case UserNotVerified, AccessDenied, UserAccountLockout:
+		return http.StatusForbidden
+	case ApplicationNotFound, UserNotFound, ExternalProviderNotFound, SessionNotFound:
+		return http.StatusNotFound
+	case DataConflict:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
 const (
 	// Error codes
-	// Error code 0 indicates an unhandled error. This means there was a server error.
-	UnhandledError        = 1
-	UnhandledErrorMessage = "an unhandled/unexpected error occured"
+	// Error code 1 indicates an unhandled error. This means there was a server error.
+	UnhandledError        ErrorCode = 1
+	UnhandledErrorMessage           = "an unhandled/unexpected error occured"
 	// Error code 5 indicates the request body could not be parsed or was otherwise invalid.
-	RequestPayloadInvalid     = 5
-	RequestBodyInvalidMessage = "the request body could not be parsed"
+	RequestPayloadInvalid     ErrorCode = 5
+	RequestBodyInvalidMessage           = "the request body could not be parsed"
 	// Error code 10 indicates the request failed validation.
 	// This means the request content was parsed but failed validation of the content.
-	RequestValidationFailure        = 10
-	RequestValidationFailureMessage = "request validation failure"
+	RequestValidationFailure        ErrorCode = 10
+	RequestValidationFailureMessage           = "request validation failure"
 	// Error code 15 indicates the requested application resource was not found.
-	ApplicationNotFound        = 15
-	ApplicationNotFoundMessage = "application not found"
+	ApplicationNotFound        ErrorCode = 15
+	ApplicationNotFoundMessage           = "application not found"
 	// Error code 20 indicates the credentials provided were invalid.
-	InvalidCredentials        = 20
-	InvalidCredentialsMessage = "invalid credentials"
+	InvalidCredentials        ErrorCode = 20
+	InvalidCredentialsMessage           = "invalid credentials"
 	// Error code 25 indicates the data provided conflicts with existing data.
 	// This means that the data provided cannot be used because it conflicts with existing data.
-	DataConflict        = 25
-	DataConflictMessage = "data conflict"
+	DataConflict        ErrorCode = 25
+	DataConflictMessage           = "data conflict"
 	// Error code 30 indicates the user has not verified their email address.
-	UserNotVerified        = 30
-	UserNotVerifiedMessage = "user not verified"
+	UserNotVerified        ErrorCode = 30
+	UserNotVerifiedMessage           = "user not verified"
 	// Error code 35 indicates the provided authorization token was invalid or has been blacklisted.
-	InvalidAuthToken        = 35
-	InvalidAuthTokenMessage = "invalid or malformed authorization token"
+	InvalidAuthToken        ErrorCode = 35
+	InvalidAuthTokenMessage           = "invalid or malformed authorization token"
 	// Error code 40 indicates the user does not have access to the requested resource.
-	AccessDenied        = 40
-	AccessDeniedMessage = "access denied"
+	AccessDenied        ErrorCode = 40
+	AccessDeniedMessage           = "access denied"
 	// Error code 45 indicates the provided verification code was invalid.
-	InvalidUserVerficationToken        = 45
-	InvalidUserVerficationTokenMessage = "invalid verification code"
+	InvalidUserVerficationToken        ErrorCode = 45
+	InvalidUserVerficationTokenMessage           = "invalid verification code"
 	// Error code 50 indicates the requested user resource was not found.
-	UserNotFound        = 50
-	UserNotFoundMessage = "user not found"
+	UserNotFound        ErrorCode = 50
+	UserNotFoundMessage           = "user not found"
 	// Error code 55 indicates the provided password reset token was invalid.
-	InvalidPasswordResetToken        = 55
-	InvalidPasswordResetTokenMessage = "invalid password reset token"
+	InvalidPasswordResetToken        ErrorCode = 55
+	InvalidPasswordResetTokenMessage           = "invalid password reset token"
 	// Error code 60 indicates the provided password reset verification code was invalid.
-	InvalidPasswordResetVerificationCode        = 60
-	InvalidPasswordResetVerificationCodeMessage = "invalid password reset verification code"
+	InvalidPasswordResetVerificationCode        ErrorCode = 60
+	InvalidPasswordResetVerificationCodeMessage           = "invalid password reset verification code"
 	// Error code 65 indicates that missing or invalid request headers were provided.
-	InvalidRequestHeaders        = 65
-	InvalidRequestHeadersMessage = "invalid or missing request headers"
+	InvalidRequestHeaders        ErrorCode = 65
+	InvalidRequestHeadersMessage           = "invalid or missing request headers"
 	// Error code 70 indicates that an authorization token is expired.
-	AuthTokenExpired        = 70
-	AuthTokenExpiredMessage = "authorization token expired"
+	AuthTokenExpired        ErrorCode = 70
+	AuthTokenExpiredMessage           = "authorization token expired"
 	// Error code 75 indicates the user's account is locked out due to too many failed login attempts.
 	// The lockout will expire 1 hour from the user's last failed login attempt.
-	UserAccountLockout        = 75
-	UserAccountLockoutMessage = "user account lockout due to too many failed login attempts"
+	UserAccountLockout        ErrorCode = 75
+	UserAccountLockoutMessage           = "user account lockout due to too many failed login attempts"
+	// Error code 80 indicates the provided multi-factor authentication code was invalid.
+	InvalidMFACode        ErrorCode = 80
+	InvalidMFACodeMessage           = "invalid multi-factor authentication code"
+	// Error code 85 indicates the multi-factor authentication challenge was not found or has expired.
+	// The user must log in again to receive a new challenge.
+	InvalidMFAChallenge        ErrorCode = 85
+	InvalidMFAChallengeMessage           = "invalid or expired multi-factor authentication challenge"
+	// Error code 90 indicates the requested external identity provider is not configured for the application.
+	ExternalProviderNotFound        ErrorCode = 90
+	ExternalProviderNotFoundMessage           = "external identity provider not found"
+	// Error code 95 indicates the authorization from an external identity provider was invalid.
+	// This means the provider denied the login, or the code, state or code verifier did not match.
+	InvalidExternalAuthorization        ErrorCode = 95
+	InvalidExternalAuthorizationMessage           = "invalid external identity provider authorization"
+	// Error code 100 indicates the requested session was not found.
+	// This means it does not belong to the user, has been revoked or the user has logged out of it.
+	SessionNotFound        ErrorCode = 100
+	SessionNotFoundMessage           = "session not found"
 )
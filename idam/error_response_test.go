@@ -0,0 +1,48 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestIsTransportError(t *testing.T) {
+	closed := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	closed.Close()
+
+	_, connectionRefused := newTestClient(t, closed).GetCurrentUser("token")
+
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"connection refused", connectionRefused, true},
+		{"transport error", &TransportError{StatusCode: http.StatusBadGateway}, true},
+		{"wrapped transport error", fmt.Errorf("get user - %w", &TransportError{StatusCode: http.StatusBadGateway}), true},
+		{"url error", &url.Error{Op: "Get", URL: "http://idam.example.com", Err: dialErr}, true},
+		{"net error", dialErr, true},
+		{"error response", ErrInvalidCredentials, false},
+		{"oauth2 error", &OAuth2Error{Code: "invalid_grant"}, false},
+		{"validation errors", ValidationErrors{{Field: "email", Rule: ValidationRuleRequired}}, false},
+		{"token verifier error", invalidAuthToken("token issuer is invalid"), false},
+		{"context cancelled", context.Canceled, false},
+		{"request cancelled", &url.Error{Op: "Get", URL: "http://idam.example.com", Err: context.Canceled}, false},
+		{"other error", errors.New("login response has no token"), false},
+		{"nil", nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransportError(tt.err); got != tt.want {
+				t.Errorf("IsTransportError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
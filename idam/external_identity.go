@@ -0,0 +1,144 @@
+package idam
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/dmars8047/strval"
+)
+
+// ExternalIdentity is a user's account at an external identity provider linked to their IDAM user
+type ExternalIdentity struct {
+	// The provider name, e.g. "google"
+	Provider string `json:"provider"`
+	// The provider's unique id for the account
+	Subject     string    `json:"subject"`
+	Email       string    `json:"email"`
+	LinkedAtUTC time.Time `json:"linked_at_utc"`
+}
+
+// ExternalLoginStartRequest is the request object for the external login start endpoint
+type ExternalLoginStartRequest struct {
+	// Where the provider redirects the user back to, it must be one of the application's allowed redirect uris
+	RedirectURI         string `json:"redirect_uri"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+}
+
+// Validate validates the external login start request
+func (request *ExternalLoginStartRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the external login start request, returning the errors of each field
+func (request *ExternalLoginStartRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateField(request.RedirectURI, "redirect_uri",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+		rule(ValidationRuleAbsoluteURL, mustBeAbsoluteURL()).with("value", request.RedirectURI))...)
+
+	validationErrors = append(validationErrors, validateField(request.State, "state",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.CodeChallenge, "code_challenge",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.CodeChallengeMethod, "code_challenge_method",
+		rule(ValidationRuleOneOf, mustBeOneOf(PKCEMethodS256)).with("values", PKCEMethodS256))...)
+
+	return validationErrors
+}
+
+// ExternalLoginStartResponse is the response object of the external login start endpoint
+type ExternalLoginStartResponse struct {
+	// The provider's authorization url to redirect the user to
+	AuthorizationURL string `json:"authorization_url"`
+}
+
+// ExternalLoginCallbackRequest is the request object for the endpoints completing an external login or identity link
+type ExternalLoginCallbackRequest struct {
+	// The authorization code the provider redirected back with
+	Code string `json:"code"`
+	// The PKCE code verifier of the code challenge sent when the login was started
+	CodeVerifier string `json:"code_verifier"`
+	// The redirect uri the login was started with
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// Validate validates the external login callback request
+func (request *ExternalLoginCallbackRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the external login callback request, returning the errors of each field
+func (request *ExternalLoginCallbackRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateField(request.Code, "code",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.CodeVerifier, "code_verifier",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.RedirectURI, "redirect_uri",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	return validationErrors
+}
+
+// ExternalLogin is a started external provider login.
+// The State and CodeVerifier are secrets that must be kept (e.g. in the user's session) until the provider redirects back.
+type ExternalLogin struct {
+	Provider         string
+	AuthorizationURL string
+	RedirectURI      string
+	State            string
+	CodeVerifier     string
+}
+
+// CallbackRequest checks the query parameters the provider redirected back with against the login,
+// returning the request that completes it. An InvalidExternalAuthorization error is returned if the provider
+// reported an error or the state is missing or does not match, and an error if the login has no state.
+// Usage: request, err := login.CallbackRequest(r.URL.Query())
+func (login *ExternalLogin) CallbackRequest(callbackQuery url.Values) (*ExternalLoginCallbackRequest, error) {
+	// Two empty states would compare equal, skipping the CSRF check
+	if login.State == "" {
+		return nil, errors.New("external login must have a state, start it with StartExternalLogin")
+	}
+
+	if providerError := callbackQuery.Get("error"); providerError != "" {
+		reason := "provider returned error " + providerError
+
+		if description := callbackQuery.Get("error_description"); description != "" {
+			reason += ": " + description
+		}
+
+		return nil, NewDetailedErrorResponse(InvalidExternalAuthorization, InvalidExternalAuthorizationMessage, reason)
+	}
+
+	state := callbackQuery.Get("state")
+
+	if state == "" {
+		return nil, NewDetailedErrorResponse(InvalidExternalAuthorization, InvalidExternalAuthorizationMessage, "missing state")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state), []byte(login.State)) != 1 {
+		return nil, NewDetailedErrorResponse(InvalidExternalAuthorization, InvalidExternalAuthorizationMessage, "state does not match")
+	}
+
+	code := callbackQuery.Get("code")
+
+	if code == "" {
+		return nil, NewDetailedErrorResponse(InvalidExternalAuthorization, InvalidExternalAuthorizationMessage, "missing authorization code")
+	}
+
+	return &ExternalLoginCallbackRequest{
+		Code:         code,
+		CodeVerifier: login.CodeVerifier,
+		RedirectURI:  login.RedirectURI,
+	}, nil
+}
@@ -0,0 +1,60 @@
+package idam_test
+
+import (
+	"testing"
+
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+func TestExternalLoginAndUnlink(t *testing.T) {
+	server := newFakeServer(t)
+
+	provider := server.AddProvider("google")
+	provider.AddAccount("subject-1", "alice@example.com")
+
+	client := server.UserAuthClient()
+
+	login, err := client.StartExternalLogin(idamtest.DefaultApplicationId, "google", "https://app.example.com/callback")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redirect, err := provider.Authorize(login.AuthorizationURL, "subject-1")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request, err := login.CallbackRequest(redirect.Query())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.CompleteExternalLogin(idamtest.DefaultApplicationId, "google", request)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	identities, err := client.ListExternalIdentities(response.Token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(identities) != 1 || identities[0].Provider != "google" || identities[0].Subject != "subject-1" {
+		t.Fatalf("identities = %+v, want the google account", identities)
+	}
+
+	user, err := client.GetCurrentUser(response.Token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Email != "alice@example.com" {
+		t.Errorf("email = %q, want the email of the provider account", user.Email)
+	}
+}
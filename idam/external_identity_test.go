@@ -0,0 +1,94 @@
+package idam
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestExternalIdentityMethodsRejectInvalidProviders(t *testing.T) {
+	calls := []struct {
+		name string
+		call func(client *UserAuthClient, provider string) error
+	}{
+		{"StartExternalLogin", func(client *UserAuthClient, provider string) error {
+			_, err := client.StartExternalLogin("app", provider, "https://app.example.com/callback")
+			return err
+		}},
+		{"CompleteExternalLogin", func(client *UserAuthClient, provider string) error {
+			_, err := client.CompleteExternalLogin("app", provider, &ExternalLoginCallbackRequest{})
+			return err
+		}},
+		{"LinkExternalIdentity", func(client *UserAuthClient, provider string) error {
+			_, err := client.LinkExternalIdentity("token", provider, &ExternalLoginCallbackRequest{})
+			return err
+		}},
+		{"UnlinkExternalIdentity", func(client *UserAuthClient, provider string) error {
+			return client.UnlinkExternalIdentity("token", provider)
+		}},
+	}
+
+	for _, tt := range calls {
+		for _, provider := range []string{"", ".", ".."} {
+			t.Run(tt.name+" "+provider, func(t *testing.T) {
+				server := newTestServer(t, respondWith(http.StatusNoContent, ""))
+
+				var validationErrors ValidationErrors
+
+				if err := tt.call(newTestClient(t, server), provider); !errors.As(err, &validationErrors) || validationErrors[0].Field != "provider" {
+					t.Errorf("error = %v, want a validation error of the provider field", err)
+				}
+
+				if got := server.requestCount(); got != 0 {
+					t.Errorf("requests = %d, want none", got)
+				}
+			})
+		}
+	}
+}
+
+func TestExternalLoginCallbackRequest(t *testing.T) {
+	login := &ExternalLogin{Provider: "google", RedirectURI: "https://app.example.com/callback", State: "state-1", CodeVerifier: "verifier-1"}
+
+	tests := []struct {
+		name          string
+		callbackQuery url.Values
+		want          error
+	}{
+		{"valid", url.Values{"code": {"code-1"}, "state": {"state-1"}}, nil},
+		{"provider error", url.Values{"error": {"access_denied"}, "state": {"state-1"}}, ErrInvalidExternalAuthorization},
+		{"no state", url.Values{"code": {"code-1"}}, ErrInvalidExternalAuthorization},
+		{"empty state", url.Values{"code": {"code-1"}, "state": {""}}, ErrInvalidExternalAuthorization},
+		{"other state", url.Values{"code": {"code-1"}, "state": {"state-2"}}, ErrInvalidExternalAuthorization},
+		{"no code", url.Values{"state": {"state-1"}}, ErrInvalidExternalAuthorization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request, err := login.CallbackRequest(tt.callbackQuery)
+
+			if tt.want != nil {
+				if !errors.Is(err, tt.want) {
+					t.Errorf("error = %v, want %v", err, tt.want)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := ExternalLoginCallbackRequest{Code: "code-1", CodeVerifier: "verifier-1", RedirectURI: "https://app.example.com/callback"}
+
+			if *request != want {
+				t.Errorf("request = %+v, want %+v", *request, want)
+			}
+		})
+	}
+
+	if _, err := (&ExternalLogin{}).CallbackRequest(url.Values{"code": {"code-1"}}); err == nil {
+		t.Error("CallbackRequest of a login without a state returned no error")
+	}
+}
@@ -0,0 +1,36 @@
+package idam_test
+
+import (
+	"testing"
+
+	"github.com/dmars8047/idamlib/idam"
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+// testPassword satisfies the default password policy
+const testPassword = "Str0ng!Passw0rd"
+
+// newFakeServer starts an idamtest.Server with the DefaultApplicationId registered, it is closed when the test finishes
+func newFakeServer(t *testing.T) *idamtest.Server {
+	t.Helper()
+
+	server := idamtest.NewServer()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// loginUser creates a verified user with the testPassword and logs them in, returning the login response
+func loginUser(t *testing.T, server *idamtest.Server, username string, email string) *idam.UserLoginResponse {
+	t.Helper()
+
+	server.CreateUser(username, email, testPassword, true)
+
+	login, err := server.UserAuthClient().Login(idamtest.DefaultApplicationId, &idam.UserLoginRequest{Email: email, Password: testPassword})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return login
+}
@@ -0,0 +1,222 @@
+package idamtest
+
+import (
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+func (server *Server) handleStartExternalLogin(w http.ResponseWriter, r *http.Request) {
+	var request idam.ExternalLoginStartRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	provider, ok := server.provider(w, r)
+
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, idam.ExternalLoginStartResponse{
+		AuthorizationURL: provider.authorizationUrlFor(&request),
+	})
+}
+
+func (server *Server) handleExternalLoginCallback(w http.ResponseWriter, r *http.Request) {
+	var request idam.ExternalLoginCallbackRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	provider, ok := server.provider(w, r)
+
+	if !ok {
+		return
+	}
+
+	account, ok := provider.exchange(&request)
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.InvalidExternalAuthorization, idam.InvalidExternalAuthorizationMessage))
+		return
+	}
+
+	usr := server.userByIdentity(provider.name, account.Subject)
+
+	if usr == nil {
+		// Existing users must log in and link the identity themselves, rather than anyone controlling the email at the provider
+		if server.userByEmail(account.Email) != nil {
+			writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage,
+				"a user with the email address already exists, log in and link the identity instead"))
+			return
+		}
+
+		usr = server.addUser(server.availableUsername(account.Email), account.Email, "")
+		usr.Verified = true
+		usr.Provider = provider.name
+		usr.verificationToken = ""
+		usr.identities = append(usr.identities, server.identity(provider, account))
+	}
+
+	if usr.MFAEnabled {
+		writeJSON(w, http.StatusOK, server.issueMFAChallenge(r.PathValue("appId"), usr))
+		return
+	}
+
+	sess := server.issueSession(r, r.PathValue("appId"), usr)
+
+	writeJSON(w, http.StatusOK, server.loginResponse(sess, usr))
+}
+
+func (server *Server) handleListIdentities(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	identities := usr.identities
+
+	if identities == nil {
+		identities = []idam.ExternalIdentity{}
+	}
+
+	writeJSON(w, http.StatusOK, identities)
+}
+
+func (server *Server) handleLinkIdentity(w http.ResponseWriter, r *http.Request) {
+	var request idam.ExternalLoginCallbackRequest
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	provider, ok := server.provider(w, r)
+
+	if !ok {
+		return
+	}
+
+	account, ok := provider.exchange(&request)
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.InvalidExternalAuthorization, idam.InvalidExternalAuthorizationMessage))
+		return
+	}
+
+	if linked := server.userByIdentity(provider.name, account.Subject); linked != nil {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "the identity is already linked to a user"))
+		return
+	}
+
+	for _, identity := range usr.identities {
+		if identity.Provider == provider.name {
+			writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "an identity from the provider is already linked"))
+			return
+		}
+	}
+
+	identity := server.identity(provider, account)
+	usr.identities = append(usr.identities, identity)
+
+	writeJSON(w, http.StatusCreated, identity)
+}
+
+func (server *Server) handleUnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	for i, identity := range usr.identities {
+		if identity.Provider != r.PathValue("provider") {
+			continue
+		}
+
+		if usr.password == "" && len(usr.identities) == 1 {
+			writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage,
+				"the only identity of a user without a password cannot be unlinked"))
+			return
+		}
+
+		usr.identities = append(usr.identities[:i:i], usr.identities[i+1:]...)
+		w.WriteHeader(http.StatusNoContent)
+
+		return
+	}
+
+	writeError(w, idam.NewErrorResponse(idam.ExternalProviderNotFound, idam.ExternalProviderNotFoundMessage))
+}
+
+// provider resolves the request's provider, writing an ExternalProviderNotFound error if it is not registered.
+// Must be called with mu held.
+func (server *Server) provider(w http.ResponseWriter, r *http.Request) (*MockProvider, bool) {
+	provider, ok := server.providers[r.PathValue("provider")]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.ExternalProviderNotFound, idam.ExternalProviderNotFoundMessage))
+		return nil, false
+	}
+
+	return provider, true
+}
+
+// identity creates the record of the provider account being linked now, must be called with mu held
+func (server *Server) identity(provider *MockProvider, account MockAccount) idam.ExternalIdentity {
+	return idam.ExternalIdentity{
+		Provider:    provider.name,
+		Subject:     account.Subject,
+		Email:       account.Email,
+		LinkedAtUTC: server.now().UTC(),
+	}
+}
+
+// availableUsername derives an unused username from the email address, must be called with mu held
+func (server *Server) availableUsername(email string) string {
+	localPart, _, _ := strings.Cut(email, "@")
+
+	base := strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return r
+		}
+
+		return -1
+	}, localPart)
+
+	if len(base) > 12 {
+		base = base[:12]
+	}
+
+	if len(base) >= 3 && server.userByUsername(base) == nil {
+		return base
+	}
+
+	for {
+		if username := base + randomCode(6); server.userByUsername(username) == nil {
+			return username
+		}
+	}
+}
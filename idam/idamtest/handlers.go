@@ -0,0 +1,492 @@
+package idamtest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+// validatable is implemented by every idam request type
+type validatable interface {
+	ValidateFields() idam.ValidationErrors
+}
+
+// passwordRequest is implemented by the idam request types with a password validated against a password policy
+type passwordRequest interface {
+	ValidateFieldsWithContext(ctx context.Context, policy idam.PasswordPolicy) (idam.ValidationErrors, error)
+}
+
+func (server *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserRegistrationRequest
+
+	if !server.requireApplication(w, r) || !decodeJSON(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if !validatePasswordRequest(w, r, &request, server.passwordPolicy(r.PathValue("appId"))) {
+		return
+	}
+
+	if server.userByEmail(request.Email) != nil || server.userByUsername(request.Username) != nil {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "username or email is already in use"))
+		return
+	}
+
+	usr := server.addUser(request.Username, request.Email, request.Password)
+
+	writeJSON(w, http.StatusCreated, idam.UserRegistrationResponse{
+		UserId:       usr.Id,
+		Username:     usr.Username,
+		Email:        usr.Email,
+		Verified:     usr.Verified,
+		Provider:     usr.Provider,
+		CreatedAtUTC: usr.CreatedAtUTC,
+		Features:     usr.Features,
+	})
+}
+
+func (server *Server) handleLogin(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserLoginRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(request.Email)
+
+	if usr == nil {
+		writeError(w, idam.NewErrorResponse(idam.InvalidCredentials, idam.InvalidCredentialsMessage))
+		return
+	}
+
+	now := server.now()
+
+	// The lockout expires LockoutDuration after the last failed attempt
+	if usr.failedLoginAttempts >= server.lockoutThreshold {
+		if now.Sub(usr.lastFailedLoginAtUTC) < LockoutDuration {
+			writeError(w, idam.NewErrorResponse(idam.UserAccountLockout, idam.UserAccountLockoutMessage))
+			return
+		}
+
+		usr.failedLoginAttempts = 0
+	}
+
+	if usr.password != request.Password {
+		usr.failedLoginAttempts++
+		usr.lastFailedLoginAtUTC = now.UTC()
+		writeError(w, idam.NewErrorResponse(idam.InvalidCredentials, idam.InvalidCredentialsMessage))
+		return
+	}
+
+	usr.failedLoginAttempts = 0
+
+	if !usr.Verified {
+		writeError(w, idam.NewErrorResponse(idam.UserNotVerified, idam.UserNotVerifiedMessage))
+		return
+	}
+
+	// Tokens are withheld until the second factor is verified
+	if usr.MFAEnabled {
+		writeJSON(w, http.StatusOK, server.issueMFAChallenge(r.PathValue("appId"), usr))
+		return
+	}
+
+	sess := server.issueSession(r, r.PathValue("appId"), usr)
+
+	writeJSON(w, http.StatusOK, server.loginResponse(sess, usr))
+}
+
+func (server *Server) handleVerifyAccount(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserAccountVerificationRequest
+
+	if !server.requireApplication(w, r) || !decodeJSON(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.users[request.UserId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	if usr.verificationToken == "" || usr.verificationToken != request.VerificationToken {
+		writeError(w, idam.NewErrorResponse(idam.InvalidUserVerficationToken, idam.InvalidUserVerficationTokenMessage))
+		return
+	}
+
+	usr.Verified = true
+	usr.verificationToken = ""
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleResendVerification(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserVerificationResendRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// The response is the same whether or not the email is registered or already verified
+	if usr := server.userByEmail(request.Email); usr != nil && !usr.Verified {
+		usr.verificationToken = randomToken(16)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (server *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	sess, ok := server.authenticate(w, r)
+
+	if !ok {
+		return
+	}
+
+	server.revokeSession(sess)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (server *Server) handleInitiatePasswordReset(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserPasswordResetInitiationRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	// The response is the same whether or not the email is registered
+	if usr := server.userByEmail(request.Email); usr != nil {
+		usr.passwordResetToken = randomToken(16)
+		usr.passwordResetCode = randomCode(6)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (server *Server) handleExecutePasswordReset(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserPasswordResetExecutionRequest
+
+	if !server.requireApplication(w, r) || !decodeJSON(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if !validatePasswordRequest(w, r, &request, server.passwordPolicy(r.PathValue("appId"))) {
+		return
+	}
+
+	usr, ok := server.users[request.UserID]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	if usr.passwordResetToken == "" || usr.passwordResetToken != request.PasswordResetToken {
+		writeError(w, idam.NewErrorResponse(idam.InvalidPasswordResetToken, idam.InvalidPasswordResetTokenMessage))
+		return
+	}
+
+	if usr.passwordResetCode != request.VerificationCode {
+		writeError(w, idam.NewErrorResponse(idam.InvalidPasswordResetVerificationCode, idam.InvalidPasswordResetVerificationCodeMessage))
+		return
+	}
+
+	usr.password = request.NewPassword
+	usr.passwordResetToken = ""
+	usr.passwordResetCode = ""
+	usr.failedLoginAttempts = 0
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserTokenRefreshRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	sess, ok := server.refreshTokens[request.RefreshToken]
+
+	if !ok || sess.appId != r.PathValue("appId") {
+		writeError(w, idam.NewErrorResponse(idam.InvalidAuthToken, idam.InvalidAuthTokenMessage))
+		return
+	}
+
+	usr, ok := server.users[sess.userId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	// Refresh tokens are single use, the old pair is replaced
+	sess = server.rotateSession(sess, usr)
+
+	writeJSON(w, http.StatusOK, server.loginResponse(sess, usr))
+}
+
+func (server *Server) handleCurrentUser(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	writeJSON(w, http.StatusOK, usr.User)
+}
+
+func (server *Server) handleUpdateUsername(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserUsernameUpdateRequest
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	if existing := server.userByUsername(request.Username); existing != nil && existing != usr {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "username is already in use"))
+		return
+	}
+
+	usr.Username = request.Username
+
+	writeJSON(w, http.StatusOK, usr.User)
+}
+
+func (server *Server) handleRequestEmailChange(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserEmailChangeRequest
+
+	if !server.requireApplication(w, r) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	if existing := server.userByEmail(request.Email); existing != nil {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "email is already in use"))
+		return
+	}
+
+	usr.pendingEmail = request.Email
+	usr.emailChangeToken = randomToken(16)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (server *Server) handleConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserEmailChangeConfirmationRequest
+
+	if !server.requireApplication(w, r) || !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.users[request.UserId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	if usr.emailChangeToken == "" || usr.emailChangeToken != request.VerificationToken {
+		writeError(w, idam.NewErrorResponse(idam.InvalidUserVerficationToken, idam.InvalidUserVerficationTokenMessage))
+		return
+	}
+
+	usr.Email = usr.pendingEmail
+	usr.pendingEmail = ""
+	usr.emailChangeToken = ""
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleChangePassword(w http.ResponseWriter, r *http.Request) {
+	var request idam.UserPasswordChangeRequest
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	sess, ok := server.authenticate(w, r)
+
+	if !ok || !decodeJSON(w, r, &request) {
+		return
+	}
+
+	if !validatePasswordRequest(w, r, &request, server.passwordPolicy(sess.appId)) {
+		return
+	}
+
+	usr, ok := server.users[sess.userId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	if usr.password != request.CurrentPassword {
+		writeError(w, idam.NewErrorResponse(idam.InvalidCredentials, idam.InvalidCredentialsMessage))
+		return
+	}
+
+	usr.password = request.NewPassword
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authenticateUser resolves the user the request's bearer token was issued to, writing an error if there is none.
+// Must be called with mu held.
+func (server *Server) authenticateUser(w http.ResponseWriter, r *http.Request) (*user, bool) {
+	sess, ok := server.authenticate(w, r)
+
+	if !ok {
+		return nil, false
+	}
+
+	usr, ok := server.users[sess.userId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return nil, false
+	}
+
+	return usr, true
+}
+
+// authenticate resolves the session of the request's bearer token, writing an error if there is none.
+// Must be called with mu held.
+func (server *Server) authenticate(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	authToken := idam.BearerTokenFromRequest(r)
+
+	if authToken == "" {
+		writeError(w, idam.NewErrorResponse(idam.InvalidRequestHeaders, idam.InvalidRequestHeadersMessage))
+		return nil, false
+	}
+
+	sess, ok := server.accessTokens[authToken]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.InvalidAuthToken, idam.InvalidAuthTokenMessage))
+		return nil, false
+	}
+
+	if !server.now().Before(sess.expiresAt) {
+		writeError(w, idam.NewErrorResponse(idam.AuthTokenExpired, idam.AuthTokenExpiredMessage))
+		return nil, false
+	}
+
+	sess.lastSeenAt = server.now()
+
+	return sess, true
+}
+
+// requireApplication writes an ApplicationNotFound error if the request's appId is not registered
+func (server *Server) requireApplication(w http.ResponseWriter, r *http.Request) bool {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if !server.applications[r.PathValue("appId")] {
+		writeError(w, idam.NewErrorResponse(idam.ApplicationNotFound, idam.ApplicationNotFoundMessage))
+		return false
+	}
+
+	return true
+}
+
+// decodeRequest decodes and validates the JSON request body, writing an error if either fails
+func decodeRequest(w http.ResponseWriter, r *http.Request, request validatable) bool {
+	if !decodeJSON(w, r, request) {
+		return false
+	}
+
+	return validateRequest(w, request.ValidateFields())
+}
+
+// validateRequest writes a RequestValidationFailure error if there are validation errors
+// Usage: if !validateRequest(w, request.ValidateFields()) { return }
+func validateRequest(w http.ResponseWriter, validationErrors idam.ValidationErrors) bool {
+	if len(validationErrors) > 0 {
+		writeError(w, validationErrors.ErrorResponse())
+		return false
+	}
+
+	return true
+}
+
+// validatePasswordRequest validates the request against the password policy like validateRequest,
+// writing an UnhandledError if the policy's breach check fails
+func validatePasswordRequest(w http.ResponseWriter, r *http.Request, request passwordRequest, policy idam.PasswordPolicy) bool {
+	validationErrors, err := request.ValidateFieldsWithContext(r.Context(), policy)
+
+	if err != nil {
+		writeError(w, idam.ErrUnhandledError)
+		return false
+	}
+
+	return validateRequest(w, validationErrors)
+}
+
+// decodeJSON decodes the JSON request body, writing a RequestPayloadInvalid error if it cannot be parsed
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, idam.NewErrorResponse(idam.RequestPayloadInvalid, idam.RequestBodyInvalidMessage))
+		return false
+	}
+
+	return true
+}
+
+// writeError writes the ErrorResponse with the status code the IDAM service uses for its code
+func writeError(w http.ResponseWriter, errorResponse *idam.ErrorResponse) {
+	idam.WriteErrorResponse(w, idam.HTTPStatusForErrorCode(errorResponse.Code), errorResponse)
+}
+
+// writeJSON writes v as a JSON body with the given status code
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(v)
+}
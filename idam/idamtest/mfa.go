@@ -0,0 +1,190 @@
+package idamtest
+
+import (
+	"net/http"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+// issueMFAChallenge starts a login awaiting the user's second factor, must be called with mu held
+func (server *Server) issueMFAChallenge(appId string, usr *user) idam.UserLoginResponse {
+	challengeId := randomToken(16)
+	expiresAt := server.now().Add(MFAChallengeLifetime)
+
+	server.mfaChallenges[challengeId] = &mfaChallenge{
+		appId:     appId,
+		userId:    usr.Id,
+		expiresAt: expiresAt,
+	}
+
+	return idam.UserLoginResponse{
+		ApplicationId: appId,
+		UserId:        usr.Id,
+		Username:      usr.Username,
+		MFAChallenge: &idam.MFAChallenge{
+			ChallengeId:  challengeId,
+			Methods:      []string{idam.MFAMethodTOTP, idam.MFAMethodRecoveryCode},
+			ExpiresAtUTC: expiresAt.UTC(),
+		},
+	}
+}
+
+func (server *Server) handleVerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var request idam.MFAVerificationRequest
+
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	challenge, ok := server.mfaChallenges[request.ChallengeId]
+
+	if !ok || !server.now().Before(challenge.expiresAt) {
+		delete(server.mfaChallenges, request.ChallengeId)
+		writeError(w, idam.NewErrorResponse(idam.InvalidMFAChallenge, idam.InvalidMFAChallengeMessage))
+		return
+	}
+
+	usr, ok := server.users[challenge.userId]
+
+	if !ok {
+		writeError(w, idam.NewErrorResponse(idam.UserNotFound, idam.UserNotFoundMessage))
+		return
+	}
+
+	if !server.verifyMFACode(usr, request.Code) {
+		writeError(w, idam.NewErrorResponse(idam.InvalidMFACode, idam.InvalidMFACodeMessage))
+		return
+	}
+
+	delete(server.mfaChallenges, request.ChallengeId)
+
+	sess := server.issueSession(r, challenge.appId, usr)
+
+	writeJSON(w, http.StatusOK, server.loginResponse(sess, usr))
+}
+
+func (server *Server) handleEnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok {
+		return
+	}
+
+	if usr.MFAEnabled {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "totp is already enabled"))
+		return
+	}
+
+	secret, err := idam.GenerateTOTPSecret()
+
+	if err != nil {
+		panic("idamtest: " + err.Error())
+	}
+
+	totp, err := idam.NewTOTP(secret)
+
+	if err != nil {
+		panic("idamtest: " + err.Error())
+	}
+
+	recoveryCodes := make([]string, recoveryCodeCount)
+
+	for i := range recoveryCodes {
+		recoveryCodes[i] = randomToken(5)
+	}
+
+	// Enrolling again before confirming replaces the pending secret
+	usr.pendingTOTPSecret = secret
+	usr.pendingRecoveryCodes = recoveryCodes
+
+	writeJSON(w, http.StatusCreated, idam.TOTPEnrollment{
+		Secret:        secret,
+		OTPAuthURI:    totp.URI(totpIssuer, usr.Email),
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+func (server *Server) handleConfirmTOTPEnrollment(w http.ResponseWriter, r *http.Request) {
+	var request idam.MFACodeRequest
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok || !decodeJSON(w, r, &request) || !validateRequest(w, request.ValidateFields()) {
+		return
+	}
+
+	if usr.pendingTOTPSecret == "" {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "there is no pending totp enrollment"))
+		return
+	}
+
+	totp, err := idam.NewTOTP(usr.pendingTOTPSecret)
+
+	if err != nil || !totp.Verify(request.Code, server.now()) {
+		writeError(w, idam.NewErrorResponse(idam.InvalidMFACode, idam.InvalidMFACodeMessage))
+		return
+	}
+
+	usr.MFAEnabled = true
+	usr.totpSecret = usr.pendingTOTPSecret
+	usr.recoveryCodes = usr.pendingRecoveryCodes
+	usr.pendingTOTPSecret = ""
+	usr.pendingRecoveryCodes = nil
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (server *Server) handleDisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var request idam.MFACodeRequest
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr, ok := server.authenticateUser(w, r)
+
+	if !ok || !decodeJSON(w, r, &request) || !validateRequest(w, request.ValidateFields()) {
+		return
+	}
+
+	if !usr.MFAEnabled {
+		writeError(w, idam.NewDetailedErrorResponse(idam.DataConflict, idam.DataConflictMessage, "totp is not enabled"))
+		return
+	}
+
+	if !server.verifyMFACode(usr, request.Code) {
+		writeError(w, idam.NewErrorResponse(idam.InvalidMFACode, idam.InvalidMFACodeMessage))
+		return
+	}
+
+	usr.MFAEnabled = false
+	usr.totpSecret = ""
+	usr.recoveryCodes = nil
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// verifyMFACode checks the code against the user's TOTP secret and recovery codes, consuming a matching recovery code.
+// Must be called with mu held.
+func (server *Server) verifyMFACode(usr *user, code string) bool {
+	if totp, err := idam.NewTOTP(usr.totpSecret); err == nil && totp.Verify(code, server.now()) {
+		return true
+	}
+
+	for i, recoveryCode := range usr.recoveryCodes {
+		if recoveryCode == code {
+			usr.recoveryCodes = append(usr.recoveryCodes[:i:i], usr.recoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,225 @@
+package idamtest
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+// AuthorizationCodeLifetime is how long an authorization code can be exchanged for after it is issued
+const AuthorizationCodeLifetime = time.Minute
+
+// oauth2Code is an authorization code issued by Authorize awaiting exchange at the token endpoint
+type oauth2Code struct {
+	appId               string
+	userId              string
+	redirectURI         string
+	codeChallenge       string
+	codeChallengeMethod string
+	nonce               string
+	scope               string
+	expiresAt           time.Time
+}
+
+// Authorize simulates the user with the email address logging in and consenting at the OAuth2 authorization url,
+// returning the redirect uri with the authorization code and state IDAM redirects back with.
+// The authorization url is the URL of an idam.AuthorizationRequest.
+func (server *Server) Authorize(authorizationUrl string, email string) (*url.URL, error) {
+	parsed, err := url.Parse(authorizationUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := parsed.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+
+	if err != nil || !redirectURI.IsAbs() {
+		return nil, errors.New("idamtest: the authorization url has an invalid redirect_uri")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if query.Get("response_type") != "code" || !server.applications[query.Get("client_id")] {
+		return nil, errors.New("idamtest: the authorization url has an invalid response_type or client_id")
+	}
+
+	if query.Get("code_challenge") == "" || query.Get("code_challenge_method") != idam.PKCEMethodS256 {
+		return nil, errors.New("idamtest: the authorization url has no S256 pkce code challenge")
+	}
+
+	usr := server.userByEmail(email)
+
+	if usr == nil || !usr.Verified {
+		return nil, errors.New("idamtest: no verified user with the email address")
+	}
+
+	code := randomToken(16)
+
+	server.oauth2Codes[code] = &oauth2Code{
+		appId:               query.Get("client_id"),
+		userId:              usr.Id,
+		redirectURI:         query.Get("redirect_uri"),
+		codeChallenge:       query.Get("code_challenge"),
+		codeChallengeMethod: query.Get("code_challenge_method"),
+		nonce:               query.Get("nonce"),
+		scope:               query.Get("scope"),
+		expiresAt:           server.now().Add(AuthorizationCodeLifetime),
+	}
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	return redirectURI, nil
+}
+
+func (server *Server) handleOAuth2Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuth2Error(w, "invalid_request", "the request body could not be parsed")
+		return
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		server.exchangeAuthorizationCode(w, r)
+	case "refresh_token":
+		server.exchangeOAuth2RefreshToken(w, r.PostForm)
+	default:
+		writeOAuth2Error(w, "unsupported_grant_type", "")
+	}
+}
+
+// exchangeAuthorizationCode redeems a single use authorization code, must be called with mu held
+func (server *Server) exchangeAuthorizationCode(w http.ResponseWriter, r *http.Request) {
+	form := r.PostForm
+
+	code, ok := server.oauth2Codes[form.Get("code")]
+	delete(server.oauth2Codes, form.Get("code"))
+
+	if !ok || !server.now().Before(code.expiresAt) || code.appId != form.Get("client_id") || code.redirectURI != form.Get("redirect_uri") {
+		writeOAuth2Error(w, "invalid_grant", "the authorization code is invalid or expired")
+		return
+	}
+
+	if !idam.VerifyPKCE(form.Get("code_verifier"), code.codeChallenge, code.codeChallengeMethod) {
+		writeOAuth2Error(w, "invalid_grant", "the code verifier does not match the code challenge")
+		return
+	}
+
+	usr, ok := server.users[code.userId]
+
+	if !ok {
+		writeOAuth2Error(w, "invalid_grant", "the user no longer exists")
+		return
+	}
+
+	sess := server.issueSession(r, code.appId, usr)
+	sess.scope = code.scope
+	sess.nonce = code.nonce
+
+	writeJSON(w, http.StatusOK, server.oauth2TokenResponse(sess, usr))
+}
+
+// exchangeOAuth2RefreshToken rotates the session of a refresh token, must be called with mu held
+func (server *Server) exchangeOAuth2RefreshToken(w http.ResponseWriter, form url.Values) {
+	sess, ok := server.refreshTokens[form.Get("refresh_token")]
+
+	if !ok || sess.appId != form.Get("client_id") {
+		writeOAuth2Error(w, "invalid_grant", "the refresh token is invalid")
+		return
+	}
+
+	usr, ok := server.users[sess.userId]
+
+	if !ok {
+		writeOAuth2Error(w, "invalid_grant", "the user no longer exists")
+		return
+	}
+
+	sess = server.rotateSession(sess, usr)
+
+	writeJSON(w, http.StatusOK, server.oauth2TokenResponse(sess, usr))
+}
+
+// oauth2TokenResponse builds the RFC 6749 token response for the session,
+// with an ID token if the openid scope was granted. Must be called with mu held.
+func (server *Server) oauth2TokenResponse(sess *session, usr *user) map[string]any {
+	response := map[string]any{
+		"access_token":  sess.accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int64(server.tokenLifetime.Seconds()),
+		"refresh_token": sess.refreshToken,
+	}
+
+	if scopes := strings.Fields(sess.scope); slices.Contains(scopes, idam.ScopeOpenID) {
+		response["id_token"] = server.idToken(sess, usr, scopes)
+	}
+
+	return response
+}
+
+// idToken signs an ID token for the session with the claims of the granted scopes, must be called with mu held.
+// ID tokens issued on refresh carry the nonce of the original authorization request.
+func (server *Server) idToken(sess *session, usr *user, scopes []string) string {
+	now := server.now()
+
+	claims := idam.IDTokenClaims{
+		Issuer:    server.URL,
+		Subject:   usr.Id,
+		Audience:  idam.Audience{sess.appId},
+		ExpiresAt: sess.expiresAt.Unix(),
+		IssuedAt:  now.Unix(),
+		Nonce:     sess.nonce,
+	}
+
+	if slices.Contains(scopes, idam.ScopeEmail) {
+		claims.Email = usr.Email
+		claims.EmailVerified = usr.Verified
+	}
+
+	if slices.Contains(scopes, idam.ScopeProfile) {
+		claims.PreferredUsername = usr.Username
+	}
+
+	return server.signToken("JWT", claims)
+}
+
+// handleDiscovery serves the OpenID Connect discovery document
+func (server *Server) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, idam.ProviderMetadata{
+		Issuer:                            server.URL,
+		AuthorizationEndpoint:             server.URL + idam.OAuth2AuthorizationUrlSuffix,
+		TokenEndpoint:                     server.URL + idam.OAuth2TokenUrlSuffix,
+		JWKSURI:                           server.URL + idam.JWKSUrlSuffix,
+		ScopesSupported:                   []string{idam.ScopeOpenID, idam.ScopeEmail, idam.ScopeProfile},
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code", "refresh_token"},
+		SubjectTypesSupported:             []string{"public"},
+		IdTokenSigningAlgValuesSupported:  []string{"RS256"},
+		CodeChallengeMethodsSupported:     []string{idam.PKCEMethodS256},
+		TokenEndpointAuthMethodsSupported: []string{"none"},
+		ClaimsSupported:                   []string{"iss", "sub", "aud", "exp", "iat", "nonce", "email", "email_verified", "preferred_username"},
+	})
+}
+
+// writeOAuth2Error writes an RFC 6749 error response
+func writeOAuth2Error(w http.ResponseWriter, code string, description string) {
+	body := map[string]string{"error": code}
+
+	if description != "" {
+		body["error_description"] = description
+	}
+
+	writeJSON(w, http.StatusBadRequest, body)
+}
@@ -0,0 +1,168 @@
+package idamtest
+
+import (
+	"errors"
+	"net/url"
+	"sync"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+// MockProvider is a fake external identity provider registered with a Server using AddProvider.
+// There is no consent screen, tests call Authorize with the authorization url returned when an external
+// login is started to simulate the user logging in to the provider and being redirected back.
+type MockProvider struct {
+	name             string
+	authorizationUrl string
+
+	mu       sync.Mutex
+	accounts map[string]MockAccount
+	codes    map[string]*authorizationCode
+}
+
+// MockAccount is a user's account at a MockProvider
+type MockAccount struct {
+	Subject string
+	Email   string
+}
+
+// authorizationCode is an issued authorization code awaiting exchange
+type authorizationCode struct {
+	account             MockAccount
+	redirectURI         string
+	codeChallenge       string
+	codeChallengeMethod string
+}
+
+// newMockProvider creates a provider whose authorization endpoint is under the server's url
+func newMockProvider(name string, serverUrl string) *MockProvider {
+	return &MockProvider{
+		name:             name,
+		authorizationUrl: serverUrl + "/idamtest/providers/" + url.PathEscape(name) + "/authorize",
+		accounts:         map[string]MockAccount{},
+		codes:            map[string]*authorizationCode{},
+	}
+}
+
+// Name returns the provider name used in the IDAM external login urls
+func (provider *MockProvider) Name() string {
+	return provider.name
+}
+
+// AddAccount creates an account at the provider that users can log in with
+func (provider *MockProvider) AddAccount(subject string, email string) MockAccount {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	account := MockAccount{Subject: subject, Email: email}
+	provider.accounts[subject] = account
+
+	return account
+}
+
+// Authorize simulates the account with the subject logging in at the authorization url,
+// returning the redirect uri with the authorization code and state the provider redirects back with.
+func (provider *MockProvider) Authorize(authorizationUrl string, subject string) (*url.URL, error) {
+	query, redirectURI, err := provider.parseAuthorizationUrl(authorizationUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	account, ok := provider.accounts[subject]
+
+	if !ok {
+		return nil, errors.New("idamtest: no account with the subject at provider " + provider.name)
+	}
+
+	code := randomToken(16)
+
+	provider.codes[code] = &authorizationCode{
+		account:             account,
+		redirectURI:         query.Get("redirect_uri"),
+		codeChallenge:       query.Get("code_challenge"),
+		codeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("code", code)
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	return redirectURI, nil
+}
+
+// Deny simulates the user refusing consent at the authorization url,
+// returning the redirect uri with the access_denied error the provider redirects back with.
+func (provider *MockProvider) Deny(authorizationUrl string) (*url.URL, error) {
+	query, redirectURI, err := provider.parseAuthorizationUrl(authorizationUrl)
+
+	if err != nil {
+		return nil, err
+	}
+
+	callbackQuery := redirectURI.Query()
+	callbackQuery.Set("error", "access_denied")
+	callbackQuery.Set("state", query.Get("state"))
+	redirectURI.RawQuery = callbackQuery.Encode()
+
+	return redirectURI, nil
+}
+
+// parseAuthorizationUrl checks the authorization url was issued for the provider, returning its query and redirect uri
+func (provider *MockProvider) parseAuthorizationUrl(authorizationUrl string) (url.Values, *url.URL, error) {
+	parsed, err := url.Parse(authorizationUrl)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if parsed.Scheme+"://"+parsed.Host+parsed.Path != provider.authorizationUrl {
+		return nil, nil, errors.New("idamtest: the authorization url was not issued for provider " + provider.name)
+	}
+
+	query := parsed.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return query, redirectURI, nil
+}
+
+// authorizationUrlFor builds the url the user is sent to for the start request
+func (provider *MockProvider) authorizationUrlFor(request *idam.ExternalLoginStartRequest) string {
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("redirect_uri", request.RedirectURI)
+	query.Set("state", request.State)
+	query.Set("code_challenge", request.CodeChallenge)
+	query.Set("code_challenge_method", request.CodeChallengeMethod)
+
+	return provider.authorizationUrl + "?" + query.Encode()
+}
+
+// exchange redeems the authorization code, which is single use, for the account that authorized it.
+// The redirect uri and PKCE code verifier must match the authorization request.
+func (provider *MockProvider) exchange(request *idam.ExternalLoginCallbackRequest) (MockAccount, bool) {
+	provider.mu.Lock()
+	defer provider.mu.Unlock()
+
+	code, ok := provider.codes[request.Code]
+
+	if !ok {
+		return MockAccount{}, false
+	}
+
+	delete(provider.codes, request.Code)
+
+	if code.redirectURI != request.RedirectURI || !idam.VerifyPKCE(request.CodeVerifier, code.codeChallenge, code.codeChallengeMethod) {
+		return MockAccount{}, false
+	}
+
+	return code.account, true
+}
@@ -0,0 +1,477 @@
+// Package idamtest provides an in-memory fake of the IDAM service for use in tests.
+//
+// Usage:
+//
+//	server := idamtest.NewServer("my-app")
+//	defer server.Close()
+//
+//	client := server.UserAuthClient()
+//	client.Register("my-app", &idam.UserRegistrationRequest{...})
+//	token, _ := server.VerificationToken("user@example.com")
+package idamtest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+const (
+	// DefaultApplicationId is the application registered when NewServer is called without application ids
+	DefaultApplicationId = "test-app"
+	// DefaultLockoutThreshold is the number of consecutive failed logins that lock a user account
+	DefaultLockoutThreshold = 5
+	// LockoutDuration is how long a lockout lasts from the user's last failed login attempt
+	LockoutDuration = time.Hour
+	// DefaultTokenLifetime is how long issued access tokens are valid for
+	DefaultTokenLifetime = 15 * time.Minute
+	// MFAChallengeLifetime is how long a login has to complete its multi-factor authentication challenge
+	MFAChallengeLifetime = 5 * time.Minute
+	// The issuer shown by authenticator apps for TOTP secrets enrolled with the server
+	totpIssuer = "idamtest"
+	// The number of recovery codes issued on TOTP enrollment
+	recoveryCodeCount = 10
+	// The key id of the server's token signing key
+	signingKeyId = "idamtest"
+)
+
+// Server is a fake IDAM service backed by an httptest.Server.
+// It implements the user account endpoints of the IDAM API with the same validation and error codes.
+// Tokens and codes that would be delivered by email are exposed through accessor methods instead.
+type Server struct {
+	*httptest.Server
+
+	mu               sync.Mutex
+	now              func() time.Time
+	lockoutThreshold int
+	tokenLifetime    time.Duration
+	signingKey       *rsa.PrivateKey
+	applications     map[string]bool
+	passwordPolicies map[string]idam.PasswordPolicy
+	users            map[string]*user
+	accessTokens     map[string]*session
+	refreshTokens    map[string]*session
+	mfaChallenges    map[string]*mfaChallenge
+	providers        map[string]*MockProvider
+	oauth2Codes      map[string]*oauth2Code
+	roles            map[idam.Role][]idam.Permission
+}
+
+// user is the server's record of a registered user
+type user struct {
+	idam.User
+	password             string
+	verificationToken    string
+	passwordResetToken   string
+	passwordResetCode    string
+	pendingEmail         string
+	emailChangeToken     string
+	totpSecret           string
+	pendingTOTPSecret    string
+	recoveryCodes        []string
+	pendingRecoveryCodes []string
+	identities           []idam.ExternalIdentity
+	failedLoginAttempts  int
+	lastFailedLoginAtUTC time.Time
+}
+
+// session is a user's login, holding its current access/refresh token pair
+type session struct {
+	id           string
+	appId        string
+	userId       string
+	userAgent    string
+	ipAddress    string
+	createdAt    time.Time
+	lastSeenAt   time.Time
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+	// The scope granted by an OAuth2 authorization and the nonce of its request, empty for sessions from a login
+	scope string
+	nonce string
+}
+
+// mfaChallenge is a login awaiting its second factor
+type mfaChallenge struct {
+	appId     string
+	userId    string
+	expiresAt time.Time
+}
+
+// NewServer starts a fake IDAM service with the given applications registered.
+// DefaultApplicationId is registered if no application ids are provided.
+// The caller should call Close when finished, to shut it down.
+func NewServer(appIds ...string) *Server {
+	signingKey, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		panic("idamtest: failed to generate signing key: " + err.Error())
+	}
+
+	server := &Server{
+		now:              time.Now,
+		lockoutThreshold: DefaultLockoutThreshold,
+		tokenLifetime:    DefaultTokenLifetime,
+		signingKey:       signingKey,
+		applications:     map[string]bool{},
+		passwordPolicies: map[string]idam.PasswordPolicy{},
+		users:            map[string]*user{},
+		accessTokens:     map[string]*session{},
+		refreshTokens:    map[string]*session{},
+		mfaChallenges:    map[string]*mfaChallenge{},
+		providers:        map[string]*MockProvider{},
+		oauth2Codes:      map[string]*oauth2Code{},
+		roles:            map[idam.Role][]idam.Permission{},
+	}
+
+	if len(appIds) == 0 {
+		appIds = []string{DefaultApplicationId}
+	}
+
+	for _, appId := range appIds {
+		server.applications[appId] = true
+	}
+
+	server.Server = httptest.NewServer(server.routes())
+
+	return server
+}
+
+// UserAuthClient returns an idam.UserAuthClient configured to call the server
+func (server *Server) UserAuthClient() *idam.UserAuthClient {
+	return idam.NewUserAuthClient(server.Client(), server.URL)
+}
+
+// TokenVerifier returns an idam.TokenVerifier that validates the access tokens issued by the server for the application.
+// It panics if the appId is empty.
+func (server *Server) TokenVerifier(appId string) *idam.TokenVerifier {
+	verifier, err := idam.NewTokenVerifier(idam.NewJWKSKeySet(server.Client(), server.URL+idam.JWKSUrlSuffix), server.URL, appId)
+
+	if err != nil {
+		panic("idamtest: " + err.Error())
+	}
+
+	return verifier
+}
+
+// AddApplication registers an application with the server
+func (server *Server) AddApplication(appId string) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.applications[appId] = true
+}
+
+// AddProvider registers a MockProvider with the name as an external identity provider for every application
+func (server *Server) AddProvider(name string) *MockProvider {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	provider := newMockProvider(name, server.URL)
+	server.providers[name] = provider
+
+	return provider
+}
+
+// SetPasswordPolicy sets the password policy enforced for the application, idam.DefaultPasswordPolicy is used by default
+func (server *Server) SetPasswordPolicy(appId string, policy idam.PasswordPolicy) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.passwordPolicies[appId] = policy
+}
+
+// SetLockoutThreshold sets the number of consecutive failed logins that lock a user account
+func (server *Server) SetLockoutThreshold(threshold int) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.lockoutThreshold = threshold
+}
+
+// SetTokenLifetime sets how long access tokens issued from now on are valid for
+func (server *Server) SetTokenLifetime(lifetime time.Duration) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.tokenLifetime = lifetime
+}
+
+// SetNow replaces the clock used for token expiry and lockouts, allowing tests to move time forward
+func (server *Server) SetNow(now func() time.Time) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.now = now
+}
+
+// CreateUser adds a user directly, bypassing registration
+func (server *Server) CreateUser(username, email, password string, verified bool) idam.User {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.addUser(username, email, password)
+	usr.Verified = verified
+
+	return usr.User
+}
+
+// DefineRole sets the permissions granted by the role, replacing any it granted before.
+// The permissions of users with the role are updated, tokens already issued to them are not.
+func (server *Server) DefineRole(role idam.Role, permissions ...idam.Permission) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	server.roles[role] = permissions
+
+	for _, usr := range server.users {
+		server.grantPermissions(usr)
+	}
+}
+
+// SetUserRoles replaces the roles of the user with the email address, returning the updated user.
+// Tokens issued to the user before the change keep the old roles and permissions until they are refreshed.
+func (server *Server) SetUserRoles(email string, roles ...idam.Role) (idam.User, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil {
+		return idam.User{}, false
+	}
+
+	usr.Roles = append([]idam.Role{}, roles...)
+	server.grantPermissions(usr)
+
+	return usr.User, true
+}
+
+// grantPermissions sets the user's permissions to those granted by their roles, must be called with mu held
+func (server *Server) grantPermissions(usr *user) {
+	permissions := []idam.Permission{}
+
+	for _, role := range usr.Roles {
+		for _, permission := range server.roles[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+
+	usr.Permissions = permissions
+}
+
+// User returns the user registered with the email address
+func (server *Server) User(email string) (idam.User, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil {
+		return idam.User{}, false
+	}
+
+	return usr.User, true
+}
+
+// VerificationToken returns the account verification token issued to the user with the email address
+func (server *Server) VerificationToken(email string) (string, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil || usr.verificationToken == "" {
+		return "", false
+	}
+
+	return usr.verificationToken, true
+}
+
+// PasswordReset returns the password reset token and verification code issued to the user with the email address
+func (server *Server) PasswordReset(email string) (resetToken string, verificationCode string, ok bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil || usr.passwordResetToken == "" {
+		return "", "", false
+	}
+
+	return usr.passwordResetToken, usr.passwordResetCode, true
+}
+
+// EmailChangeToken returns the verification token sent to the new address when the user with the email address requested an email change
+func (server *Server) EmailChangeToken(email string) (string, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil || usr.emailChangeToken == "" {
+		return "", false
+	}
+
+	return usr.emailChangeToken, true
+}
+
+// TOTPSecret returns the confirmed TOTP secret of the user with the email address, for generating codes with idam.NewTOTP
+func (server *Server) TOTPSecret(email string) (string, bool) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	usr := server.userByEmail(email)
+
+	if usr == nil || usr.totpSecret == "" {
+		return "", false
+	}
+
+	return usr.totpSecret, true
+}
+
+// addUser creates and stores a new unverified user, must be called with mu held
+func (server *Server) addUser(username, email, password string) *user {
+	usr := &user{
+		User: idam.User{
+			Id:           randomToken(16),
+			Username:     username,
+			Email:        email,
+			Type:         idam.StandardUserType,
+			Provider:     "idam",
+			CreatedAtUTC: server.now().UTC(),
+			Features:     []string{},
+			Roles:        []idam.Role{},
+			Permissions:  []idam.Permission{},
+		},
+		password:          password,
+		verificationToken: randomToken(16),
+	}
+
+	server.users[usr.Id] = usr
+
+	return usr
+}
+
+// userByIdentity finds the user the external identity is linked to, must be called with mu held
+func (server *Server) userByIdentity(provider string, subject string) *user {
+	for _, usr := range server.users {
+		for _, identity := range usr.identities {
+			if identity.Provider == provider && identity.Subject == subject {
+				return usr
+			}
+		}
+	}
+
+	return nil
+}
+
+// passwordPolicy returns the password policy of the application, must be called with mu held
+func (server *Server) passwordPolicy(appId string) idam.PasswordPolicy {
+	if policy, ok := server.passwordPolicies[appId]; ok {
+		return policy
+	}
+
+	return idam.DefaultPasswordPolicy()
+}
+
+// userByEmail finds a user by email address ignoring case, must be called with mu held
+func (server *Server) userByEmail(email string) *user {
+	for _, usr := range server.users {
+		if strings.EqualFold(usr.Email, email) {
+			return usr
+		}
+	}
+
+	return nil
+}
+
+// userByUsername finds a user by username ignoring case, must be called with mu held
+func (server *Server) userByUsername(username string) *user {
+	for _, usr := range server.users {
+		if strings.EqualFold(usr.Username, username) {
+			return usr
+		}
+	}
+
+	return nil
+}
+
+// routes registers a handler for every IDAM endpoint the server implements
+func (server *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	pathParams := strings.NewReplacer(":appId", "{appId}", ":provider", "{provider}", ":sessionId", "{sessionId}")
+
+	handle := func(method, urlSuffix string, handler http.HandlerFunc) {
+		mux.HandleFunc(method+" "+pathParams.Replace(urlSuffix), handler)
+	}
+
+	handle(http.MethodPost, idam.UserRegistrationAccountUrlSuffix, server.handleRegister)
+	handle(http.MethodPost, idam.UserLoginUrlSuffix, server.handleLogin)
+	handle(http.MethodPut, idam.UserAccountVerifyAccountUrlSuffix, server.handleVerifyAccount)
+	handle(http.MethodPost, idam.UserLogoutUrlSuffix, server.handleLogout)
+	handle(http.MethodPost, idam.InitiateUserPasswordResetUrl, server.handleInitiatePasswordReset)
+	handle(http.MethodPut, idam.ExecuteUserPasswordResetUrl, server.handleExecutePasswordReset)
+	handle(http.MethodPost, idam.UserTokenRefreshUrlSuffix, server.handleRefresh)
+	handle(http.MethodGet, idam.UserTokenValidationUrlSuffix, server.handleCurrentUser)
+	handle(http.MethodGet, idam.CurrentUserUrlSuffix, server.handleCurrentUser)
+	handle(http.MethodPut, idam.CurrentUserUsernameUrlSuffix, server.handleUpdateUsername)
+	handle(http.MethodPut, idam.CurrentUserEmailChangeUrlSuffix, server.handleRequestEmailChange)
+	handle(http.MethodPut, idam.ConfirmUserEmailChangeUrlSuffix, server.handleConfirmEmailChange)
+	handle(http.MethodPut, idam.CurrentUserPasswordUrlSuffix, server.handleChangePassword)
+	handle(http.MethodPost, idam.ResendUserVerificationUrlSuffix, server.handleResendVerification)
+	handle(http.MethodPost, idam.UserMFAVerificationUrlSuffix, server.handleVerifyMFA)
+	handle(http.MethodPost, idam.CurrentUserTOTPUrlSuffix, server.handleEnrollTOTP)
+	handle(http.MethodPut, idam.CurrentUserTOTPConfirmationUrlSuffix, server.handleConfirmTOTPEnrollment)
+	handle(http.MethodPut, idam.CurrentUserTOTPDisableUrlSuffix, server.handleDisableTOTP)
+	handle(http.MethodPost, idam.ExternalLoginStartUrlSuffix, server.handleStartExternalLogin)
+	handle(http.MethodPost, idam.ExternalLoginCallbackUrlSuffix, server.handleExternalLoginCallback)
+	handle(http.MethodGet, idam.CurrentUserIdentitiesUrlSuffix, server.handleListIdentities)
+	handle(http.MethodPost, idam.CurrentUserIdentityUrlSuffix, server.handleLinkIdentity)
+	handle(http.MethodDelete, idam.CurrentUserIdentityUrlSuffix, server.handleUnlinkIdentity)
+	handle(http.MethodGet, idam.CurrentUserSessionsUrlSuffix, server.handleListSessions)
+	handle(http.MethodDelete, idam.CurrentUserSessionUrlSuffix, server.handleRevokeSession)
+	handle(http.MethodDelete, idam.CurrentUserOtherSessionsUrlSuffix, server.handleRevokeOtherSessions)
+	handle(http.MethodPost, idam.OAuth2TokenUrlSuffix, server.handleOAuth2Token)
+	handle(http.MethodGet, idam.JWKSUrlSuffix, server.handleJWKS)
+	handle(http.MethodGet, idam.OIDCDiscoveryUrlSuffix, server.handleDiscovery)
+
+	return mux
+}
+
+// randomToken returns a random hex encoded token of n bytes
+func randomToken(n int) string {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		panic("idamtest: failed to generate random token: " + err.Error())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// randomCode returns a random numeric code of the given number of digits
+func randomCode(digits int) string {
+	b := make([]byte, digits)
+
+	if _, err := rand.Read(b); err != nil {
+		panic("idamtest: failed to generate random code: " + err.Error())
+	}
+
+	for i := range b {
+		b[i] = '0' + b[i]%10
+	}
+
+	return string(b)
+}
@@ -0,0 +1,180 @@
+package idamtest_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dmars8047/idamlib/idam"
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+const testPassword = "Str0ng!Passw0rd"
+
+// newServer starts a Server with the DefaultApplicationId registered, it is closed when the test finishes
+func newServer(t *testing.T) *idamtest.Server {
+	t.Helper()
+
+	server := idamtest.NewServer()
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestServerRegisterVerifyAndLogin(t *testing.T) {
+	server := newServer(t)
+	client := server.UserAuthClient()
+
+	registered, err := client.Register(idamtest.DefaultApplicationId, &idam.UserRegistrationRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: testPassword,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if registered.Verified {
+		t.Error("registered user is verified, want unverified until the account is verified")
+	}
+
+	login := &idam.UserLoginRequest{Email: "alice@example.com", Password: testPassword}
+
+	if _, err := client.Login(idamtest.DefaultApplicationId, login); !errors.Is(err, idam.ErrUserNotVerified) {
+		t.Fatalf("Login error = %v, want %v", err, idam.ErrUserNotVerified)
+	}
+
+	verificationToken, ok := server.VerificationToken("alice@example.com")
+
+	if !ok {
+		t.Fatal("no verification token was issued")
+	}
+
+	err = client.VerifyAccount(idamtest.DefaultApplicationId, &idam.UserAccountVerificationRequest{
+		UserId:            registered.UserId,
+		VerificationToken: verificationToken,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	response, err := client.Login(idamtest.DefaultApplicationId, login)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := client.GetCurrentUser(response.Token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Id != registered.UserId || !user.Verified {
+		t.Errorf("current user = %+v, want the verified registered user", user)
+	}
+}
+
+func TestServerErrorCodes(t *testing.T) {
+	server := newServer(t)
+	server.CreateUser("alice", "alice@example.com", testPassword, true)
+	client := server.UserAuthClient()
+
+	tests := []struct {
+		name       string
+		call       func() error
+		want       error
+		wantStatus int
+	}{
+		{"invalid registration", func() error {
+			_, err := client.Register(idamtest.DefaultApplicationId, &idam.UserRegistrationRequest{Username: "b", Email: "bob", Password: "weak"})
+			return err
+		}, idam.ErrRequestValidationFailure, http.StatusBadRequest},
+		{"email already registered", func() error {
+			_, err := client.Register(idamtest.DefaultApplicationId, &idam.UserRegistrationRequest{Username: "alice2", Email: "ALICE@example.com", Password: testPassword})
+			return err
+		}, idam.ErrDataConflict, http.StatusConflict},
+		{"unknown application", func() error {
+			_, err := client.Login("other-app", &idam.UserLoginRequest{Email: "alice@example.com", Password: testPassword})
+			return err
+		}, idam.ErrApplicationNotFound, http.StatusNotFound},
+		{"wrong password", func() error {
+			_, err := client.Login(idamtest.DefaultApplicationId, &idam.UserLoginRequest{Email: "alice@example.com", Password: "Wr0ng!Password"})
+			return err
+		}, idam.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"unknown email", func() error {
+			_, err := client.Login(idamtest.DefaultApplicationId, &idam.UserLoginRequest{Email: "bob@example.com", Password: testPassword})
+			return err
+		}, idam.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"unknown token", func() error {
+			_, err := client.GetCurrentUser("not-a-token")
+			return err
+		}, idam.ErrInvalidAuthToken, http.StatusUnauthorized},
+		{"no token", func() error {
+			_, err := client.GetCurrentUser("")
+			return err
+		}, idam.ErrInvalidRequestHeaders, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("error = %v, want %v", err, tt.want)
+			}
+
+			if errorResponse, _ := idam.AsErrorResponse(err); errorResponse.HTTPStatus != tt.wantStatus {
+				t.Errorf("status = %d, want %d", errorResponse.HTTPStatus, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestServerRegistrationValidationErrors(t *testing.T) {
+	client := newServer(t).UserAuthClient()
+
+	_, err := client.Register(idamtest.DefaultApplicationId, &idam.UserRegistrationRequest{Username: "alice", Email: "not-an-email", Password: testPassword})
+
+	errorResponse, ok := idam.AsErrorResponse(err)
+
+	if !ok {
+		t.Fatalf("error = %v, want an ErrorResponse", err)
+	}
+
+	if len(errorResponse.ValidationErrors) == 0 || len(errorResponse.ValidationErrors.Field("email")) != len(errorResponse.ValidationErrors) {
+		t.Errorf("validation errors = %v, want errors of the email field only", errorResponse.ValidationErrors)
+	}
+}
+
+func TestServerLockout(t *testing.T) {
+	server := newServer(t)
+	server.CreateUser("alice", "alice@example.com", testPassword, true)
+	server.SetLockoutThreshold(2)
+
+	now := time.Now()
+	server.SetNow(func() time.Time { return now })
+
+	client := server.UserAuthClient()
+	wrong := &idam.UserLoginRequest{Email: "alice@example.com", Password: "Wr0ng!Password"}
+	right := &idam.UserLoginRequest{Email: "alice@example.com", Password: testPassword}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Login(idamtest.DefaultApplicationId, wrong); !errors.Is(err, idam.ErrInvalidCredentials) {
+			t.Fatalf("Login error = %v, want %v", err, idam.ErrInvalidCredentials)
+		}
+	}
+
+	if _, err := client.Login(idamtest.DefaultApplicationId, right); !errors.Is(err, idam.ErrUserAccountLockout) {
+		t.Fatalf("Login with the right password while locked out error = %v, want %v", err, idam.ErrUserAccountLockout)
+	}
+
+	now = now.Add(idamtest.LockoutDuration)
+
+	if _, err := client.Login(idamtest.DefaultApplicationId, right); err != nil {
+		t.Fatalf("Login after the lockout expired returned error %v", err)
+	}
+}
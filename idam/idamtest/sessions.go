@@ -0,0 +1,99 @@
+package idamtest
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+func (server *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	current, ok := server.authenticate(w, r)
+
+	if !ok {
+		return
+	}
+
+	sessions := []idam.Session{}
+
+	for _, sess := range server.userSessions(current.userId) {
+		sessions = append(sessions, idam.Session{
+			Id:            sess.id,
+			ApplicationId: sess.appId,
+			UserAgent:     sess.userAgent,
+			IPAddress:     sess.ipAddress,
+			CreatedAtUTC:  sess.createdAt.UTC(),
+			LastSeenAtUTC: sess.lastSeenAt.UTC(),
+			Current:       sess.id == current.id,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, sessions)
+}
+
+func (server *Server) handleRevokeSession(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	current, ok := server.authenticate(w, r)
+
+	if !ok {
+		return
+	}
+
+	for _, sess := range server.userSessions(current.userId) {
+		if sess.id == r.PathValue("sessionId") {
+			server.revokeSession(sess)
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+	}
+
+	writeError(w, idam.NewErrorResponse(idam.SessionNotFound, idam.SessionNotFoundMessage))
+}
+
+func (server *Server) handleRevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	current, ok := server.authenticate(w, r)
+
+	if !ok {
+		return
+	}
+
+	for _, sess := range server.userSessions(current.userId) {
+		if sess.id != current.id {
+			server.revokeSession(sess)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userSessions returns the user's sessions that have not been revoked, oldest first. Must be called with mu held.
+func (server *Server) userSessions(userId string) []*session {
+	var sessions []*session
+
+	// Every session that has not been revoked holds a refresh token
+	for _, sess := range server.refreshTokens {
+		if sess.userId == userId {
+			sessions = append(sessions, sess)
+		}
+	}
+
+	slices.SortFunc(sessions, func(a, b *session) int {
+		if c := a.createdAt.Compare(b.createdAt); c != 0 {
+			return c
+		}
+
+		return strings.Compare(a.id, b.id)
+	})
+
+	return sessions
+}
@@ -0,0 +1,127 @@
+package idamtest
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+
+	"github.com/dmars8047/idamlib/idam"
+)
+
+// issueSession starts a new session for the user logging in with the request, must be called with mu held
+func (server *Server) issueSession(r *http.Request, appId string, usr *user) *session {
+	ipAddress, _, err := net.SplitHostPort(r.RemoteAddr)
+
+	if err != nil {
+		ipAddress = r.RemoteAddr
+	}
+
+	sess := &session{
+		id:        randomToken(16),
+		appId:     appId,
+		userId:    usr.Id,
+		userAgent: r.UserAgent(),
+		ipAddress: ipAddress,
+		createdAt: server.now(),
+	}
+
+	server.issueTokens(sess, usr)
+
+	return sess
+}
+
+// rotateSession replaces the session's access/refresh token pair, must be called with mu held
+func (server *Server) rotateSession(sess *session, usr *user) *session {
+	server.revokeSession(sess)
+
+	rotated := *sess
+	server.issueTokens(&rotated, usr)
+
+	return &rotated
+}
+
+// issueTokens creates a new access/refresh token pair for the session, must be called with mu held
+func (server *Server) issueTokens(sess *session, usr *user) {
+	now := server.now()
+
+	sess.refreshToken = randomToken(32)
+	sess.expiresAt = now.Add(server.tokenLifetime)
+	sess.lastSeenAt = now
+
+	sess.accessToken = server.signToken(idam.AccessTokenType, idam.TokenClaims{
+		Issuer:      server.URL,
+		Subject:     usr.Id,
+		Audience:    idam.Audience{sess.appId},
+		ExpiresAt:   sess.expiresAt.Unix(),
+		IssuedAt:    now.Unix(),
+		TokenId:     randomToken(16),
+		SessionId:   sess.id,
+		Username:    usr.Username,
+		Email:       usr.Email,
+		Verified:    usr.Verified,
+		Features:    usr.Features,
+		Roles:       usr.Roles,
+		Permissions: usr.Permissions,
+	})
+
+	server.accessTokens[sess.accessToken] = sess
+	server.refreshTokens[sess.refreshToken] = sess
+}
+
+// revokeSession invalidates both tokens of the session, must be called with mu held
+func (server *Server) revokeSession(sess *session) {
+	delete(server.accessTokens, sess.accessToken)
+	delete(server.refreshTokens, sess.refreshToken)
+}
+
+// loginResponse builds the login response for the session, must be called with mu held
+func (server *Server) loginResponse(sess *session, usr *user) idam.UserLoginResponse {
+	return idam.UserLoginResponse{
+		Token:         sess.accessToken,
+		TokenType:     "Bearer",
+		ApplicationId: sess.appId,
+		ExpiresIn:     int64(server.tokenLifetime.Seconds()),
+		UserId:        usr.Id,
+		Username:      usr.Username,
+		RefreshToken:  sess.refreshToken,
+	}
+}
+
+// signToken serializes the claims as an RS256 signed JWT with the typ header
+func (server *Server) signToken(typ string, claims any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": signingKeyId, "typ": typ})
+	payload, _ := json.Marshal(claims)
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signingInput))
+
+	signature, err := rsa.SignPKCS1v15(rand.Reader, server.signingKey, crypto.SHA256, digest[:])
+
+	if err != nil {
+		panic("idamtest: failed to sign token: " + err.Error())
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// handleJWKS serves the public half of the token signing key
+func (server *Server) handleJWKS(w http.ResponseWriter, r *http.Request) {
+	publicKey := server.signingKey.PublicKey
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": signingKeyId,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
@@ -0,0 +1,322 @@
+package idam
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// JWKSUrlSuffix is the url suffix of the IDAM service's JSON Web Key Set
+	JWKSUrlSuffix = "/.well-known/jwks.json"
+	// DefaultJWKSCacheTTL is how long fetched signing keys are cached before they are fetched again
+	DefaultJWKSCacheTTL = time.Hour
+	// Minimum time between fetches triggered by a token signed with an unknown key id
+	jwksMinRefreshInterval = 30 * time.Second
+	// Minimum time after a failed fetch before the key set is fetched again
+	jwksFetchFailureBackoff = 5 * time.Second
+	// Maximum duration of a fetch, which is not cancelled with the context of the caller that started it
+	jwksFetchTimeout = 30 * time.Second
+)
+
+// ErrSigningKeyNotFound is returned by a KeySet when no key exists for the requested key id
+var ErrSigningKeyNotFound = errors.New("signing key not found")
+
+// KeySet provides the public keys used to verify token signatures
+type KeySet interface {
+	// Key returns the public key with the given key id.
+	// ErrSigningKeyNotFound is returned if the key set has no such key.
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JWKSKeySet is a KeySet backed by a remote JSON Web Key Set.
+// Keys are cached and fetched again when the cache expires or a token is signed with an unknown key id,
+// so signing key rotation is picked up without a restart. It is safe for concurrent use.
+// Concurrent callers share a single in-flight fetch, and fetching is paused for a short time after a fetch fails.
+type JWKSKeySet struct {
+	doer    Doer
+	jwksUrl string
+
+	mu        sync.Mutex
+	cacheTTL  time.Duration
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+	failedAt  time.Time
+	lastErr   error
+	inFlight  *jwksFetchCall
+}
+
+// jwksFetchCall is a fetch in progress that callers can wait on
+type jwksFetchCall struct {
+	done chan struct{}
+	err  error
+}
+
+// NewJWKSKeySet creates a JWKSKeySet fetching keys from the jwksUrl with the httpClient
+// Usage: NewJWKSKeySet(http.DefaultClient, "https://idam.example.com/.well-known/jwks.json")
+func NewJWKSKeySet(httpClient *http.Client, jwksUrl string) *JWKSKeySet {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &JWKSKeySet{
+		doer:     httpClient,
+		jwksUrl:  jwksUrl,
+		cacheTTL: DefaultJWKSCacheTTL,
+	}
+}
+
+// SetCacheTTL sets how long fetched keys are cached before they are fetched again
+func (keySet *JWKSKeySet) SetCacheTTL(ttl time.Duration) {
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+
+	keySet.cacheTTL = ttl
+}
+
+// Key returns the public key with the given key id, fetching the key set if required
+func (keySet *JWKSKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	keySet.mu.Lock()
+	call := keySet.refresh(ctx, kid)
+	keySet.mu.Unlock()
+
+	if call != nil {
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	keySet.mu.Lock()
+	defer keySet.mu.Unlock()
+
+	// Keep serving the previously fetched keys if the key set is temporarily unavailable
+	if keySet.keys == nil {
+		return nil, keySet.lastErr
+	}
+
+	key, ok := keySet.lookup(kid)
+
+	if !ok {
+		if call != nil && call.err != nil {
+			return nil, call.err
+		}
+
+		return nil, ErrSigningKeyNotFound
+	}
+
+	return key, nil
+}
+
+// refresh returns the fetch a lookup of the key id has to wait on, nil if the cached keys are used.
+// A fetch already in progress is joined, and no fetch is started within jwksFetchFailureBackoff of a failed one.
+// Must be called with mu held.
+func (keySet *JWKSKeySet) refresh(ctx context.Context, kid string) *jwksFetchCall {
+	sinceFetch := time.Since(keySet.fetchedAt)
+	expired := keySet.keys == nil || sinceFetch > keySet.cacheTTL
+
+	// The keys may have been rotated since the last fetch
+	_, found := keySet.lookup(kid)
+	rotated := !found && sinceFetch > jwksMinRefreshInterval
+
+	if !expired && !rotated {
+		return nil
+	}
+
+	if keySet.inFlight != nil {
+		return keySet.inFlight
+	}
+
+	if time.Since(keySet.failedAt) < jwksFetchFailureBackoff {
+		return nil
+	}
+
+	call := &jwksFetchCall{done: make(chan struct{})}
+	keySet.inFlight = call
+
+	// The fetch outlives a caller that gives up waiting, it is shared by every caller waiting on it
+	go keySet.run(context.WithoutCancel(ctx), call)
+
+	return call
+}
+
+// run fetches the key set for the call, storing the keys or recording the failure
+func (keySet *JWKSKeySet) run(ctx context.Context, call *jwksFetchCall) {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	keys, err := keySet.fetch(ctx)
+
+	keySet.mu.Lock()
+
+	if err != nil {
+		keySet.failedAt = time.Now()
+		keySet.lastErr = err
+	} else {
+		keySet.keys = keys
+		keySet.fetchedAt = time.Now()
+	}
+
+	call.err = err
+	keySet.inFlight = nil
+	keySet.mu.Unlock()
+
+	close(call.done)
+}
+
+// lookup finds a cached key, a token without a key id matches a key set containing a single key
+func (keySet *JWKSKeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	if kid == "" && len(keySet.keys) == 1 {
+		for _, key := range keySet.keys {
+			return key, true
+		}
+	}
+
+	key, ok := keySet.keys[kid]
+
+	return key, ok
+}
+
+// jsonWebKeySet is the JSON representation of a JSON Web Key Set (RFC 7517)
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is the JSON representation of a single JSON Web Key (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetch downloads and parses the key set
+func (keySet *JWKSKeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(retryableContext(ctx), http.MethodGet, keySet.jwksUrl, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := keySet.doer.Do(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching signing keys from idam service - unexpected status code %d", response.StatusCode)
+	}
+
+	var set jsonWebKeySet
+
+	err = json.NewDecoder(response.Body).Decode(&set)
+
+	if err != nil {
+		return nil, fmt.Errorf("error decoding signing keys from idam service - %v", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, jwk := range set.Keys {
+		// Skip keys that are not used for signatures or have an unsupported type
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.publicKey()
+
+		if err != nil {
+			continue
+		}
+
+		keys[jwk.Kid] = key
+	}
+
+	return keys, nil
+}
+
+// publicKey converts the JSON Web Key into an RSA or ECDSA public key
+func (jwk *jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64BigInt(jwk.N)
+
+		if err != nil {
+			return nil, err
+		}
+
+		e, err := decodeBase64BigInt(jwk.E)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+			return nil, errors.New("invalid rsa public exponent")
+		}
+
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+
+		x, err := decodeBase64BigInt(jwk.X)
+
+		if err != nil {
+			return nil, err
+		}
+
+		y, err := decodeBase64BigInt(jwk.Y)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+// decodeBase64BigInt decodes a base64url encoded big-endian integer
+func decodeBase64BigInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decoded) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+
+	return new(big.Int).SetBytes(decoded), nil
+}
@@ -0,0 +1,126 @@
+package idam
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+// newTestSigningKey generates an RSA key for signing test tokens
+func newTestSigningKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+// jwksJSON returns the JSON Web Key Set containing the public key of the signing key
+func jwksJSON(t *testing.T, kid string, key *rsa.PrivateKey) []byte {
+	t.Helper()
+
+	body, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{{
+		Kty: "RSA",
+		Kid: kid,
+		Use: "sig",
+		N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return body
+}
+
+// jwksHandler returns a handler answering with the JSON Web Key Set once the release channel is closed
+func jwksHandler(body []byte, release chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		respondWith(http.StatusOK, string(body))(w, r)
+	}
+}
+
+func TestJWKSKeySetBacksOffAfterFailedFetch(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusInternalServerError, ""))
+	keySet := NewJWKSKeySet(server.Client(), server.URL)
+
+	for i := 0; i < 3; i++ {
+		if _, err := keySet.Key(context.Background(), "key-1"); err == nil {
+			t.Fatal("Key returned no error, want the fetch error")
+		}
+	}
+
+	if got := server.requestCount(); got != 1 {
+		t.Errorf("attempts = %d, want 1 within the backoff after a failed fetch", got)
+	}
+}
+
+func TestJWKSKeySetSharesConcurrentFetches(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestServer(t, jwksHandler(jwksJSON(t, "key-1", newTestSigningKey(t)), release))
+	keySet := NewJWKSKeySet(server.Client(), server.URL)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := keySet.Key(context.Background(), "key-1")
+			errs <- err
+		}()
+	}
+
+	close(release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Key returned error %v", err)
+		}
+	}
+
+	if got := server.requestCount(); got != 1 {
+		t.Errorf("attempts = %d, want 1 shared fetch", got)
+	}
+}
+
+func TestJWKSKeySetCallerCancellationDoesNotAbortFetch(t *testing.T) {
+	release := make(chan struct{})
+	server := newTestServer(t, jwksHandler(jwksJSON(t, "key-1", newTestSigningKey(t)), release))
+	keySet := NewJWKSKeySet(server.Client(), server.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := keySet.Key(ctx, "key-1"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Key error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+
+	if _, err := keySet.Key(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Key returned error %v", err)
+	}
+
+	if got := server.requestCount(); got != 1 {
+		t.Errorf("attempts = %d, want the cancelled caller's fetch to be completed and shared", got)
+	}
+}
@@ -0,0 +1,185 @@
+package idam
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// jwtHeader is the JOSE header of a signed JWT
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ"`
+}
+
+// AccessTokenType is the typ header of access tokens issued by the IDAM service (RFC 9068).
+// It stops an ID token being accepted as an access token and the other way around.
+const AccessTokenType = "at+jwt"
+
+// jwtKind is the kind of token a JWT is expected to be, told apart by its typ header
+type jwtKind int
+
+const (
+	accessTokenJWT jwtKind = iota
+	idTokenJWT
+)
+
+// acceptsType reports whether the typ header is valid for the kind of token.
+// The value is compared case insensitively and may have the "application/" media type prefix.
+func (kind jwtKind) acceptsType(typ string) bool {
+	typ = strings.TrimPrefix(strings.ToLower(typ), "application/")
+
+	switch kind {
+	case accessTokenJWT:
+		return typ == AccessTokenType
+	default:
+		// ID tokens are typed as plain JWTs or not typed at all
+		return typ == "jwt" || typ == ""
+	}
+}
+
+// jwtSigningAlgorithm describes how a JWT signature is verified for an alg header value
+type jwtSigningAlgorithm struct {
+	hash crypto.Hash
+	// The size in bytes of each of the r and s values for ECDSA signatures
+	ecdsaKeySize int
+}
+
+var jwtSigningAlgorithms = map[string]jwtSigningAlgorithm{
+	"RS256": {hash: crypto.SHA256},
+	"RS384": {hash: crypto.SHA384},
+	"RS512": {hash: crypto.SHA512},
+	"ES256": {hash: crypto.SHA256, ecdsaKeySize: 32},
+	"ES384": {hash: crypto.SHA384, ecdsaKeySize: 48},
+	"ES512": {hash: crypto.SHA512, ecdsaKeySize: 66},
+}
+
+// verifyJWT verifies the signature of a compact serialized JWT of the kind with a key from the key set
+// and decodes its payload into claims. Malformed, incorrectly signed or mistyped tokens are reported as InvalidAuthToken.
+// Errors retrieving keys from the key set other than ErrSigningKeyNotFound are returned as is.
+func verifyJWT(ctx context.Context, keys KeySet, token string, kind jwtKind, claims any) error {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return invalidAuthToken("token is not a valid jwt")
+	}
+
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+
+	if err != nil {
+		return invalidAuthToken("token header is not valid base64url")
+	}
+
+	var header jwtHeader
+
+	if err = json.Unmarshal(headerBytes, &header); err != nil {
+		return invalidAuthToken("token header is not valid json")
+	}
+
+	if !kind.acceptsType(header.Typ) {
+		return invalidAuthToken("token type is invalid")
+	}
+
+	algorithm, ok := jwtSigningAlgorithms[header.Alg]
+
+	if !ok {
+		return invalidAuthToken("token signing algorithm is not supported")
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return invalidAuthToken("token signature is not valid base64url")
+	}
+
+	key, err := keys.Key(ctx, header.Kid)
+
+	if errors.Is(err, ErrSigningKeyNotFound) {
+		return invalidAuthToken("token signing key is unknown")
+	}
+
+	if err != nil {
+		return err
+	}
+
+	hasher := algorithm.hash.New()
+	hasher.Write([]byte(parts[0] + "." + parts[1]))
+	digest := hasher.Sum(nil)
+
+	if !verifyJWTSignature(key, algorithm, digest, signature) {
+		return invalidAuthToken("token signature is invalid")
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return invalidAuthToken("token payload is not valid base64url")
+	}
+
+	if err = json.Unmarshal(payloadBytes, claims); err != nil {
+		return invalidAuthToken("token payload is not valid json")
+	}
+
+	return nil
+}
+
+// unverifiedExpiry reads the exp claim of a JWT without verifying the token, zero if it is not a JWT or has no exp claim.
+// It must only be used to decide when a token held by the client is refreshed, never to trust the token.
+func unverifiedExpiry(token string) int64 {
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return 0
+	}
+
+	payloadBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return 0
+	}
+
+	var claims struct {
+		ExpiresAt int64 `json:"exp"`
+	}
+
+	if json.Unmarshal(payloadBytes, &claims) != nil {
+		return 0
+	}
+
+	return claims.ExpiresAt
+}
+
+// verifyJWTSignature checks the signature of the digest, the key type must match the algorithm
+func verifyJWTSignature(key crypto.PublicKey, algorithm jwtSigningAlgorithm, digest []byte, signature []byte) bool {
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if algorithm.ecdsaKeySize != 0 {
+			return false
+		}
+
+		return rsa.VerifyPKCS1v15(publicKey, algorithm.hash, digest, signature) == nil
+	case *ecdsa.PublicKey:
+		if algorithm.ecdsaKeySize == 0 || len(signature) != 2*algorithm.ecdsaKeySize {
+			return false
+		}
+
+		r := new(big.Int).SetBytes(signature[:algorithm.ecdsaKeySize])
+		s := new(big.Int).SetBytes(signature[algorithm.ecdsaKeySize:])
+
+		return ecdsa.Verify(publicKey, digest, r, s)
+	default:
+		return false
+	}
+}
+
+// invalidAuthToken creates an InvalidAuthToken ErrorResponse with the reason as its detail
+func invalidAuthToken(reason string) *ErrorResponse {
+	return NewDetailedErrorResponse(InvalidAuthToken, InvalidAuthTokenMessage, reason)
+}
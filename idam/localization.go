@@ -0,0 +1,207 @@
+package idam
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Localizer renders error and validation messages in the end user's language
+type Localizer interface {
+	// Supports reports whether the localizer has messages for the language
+	Supports(language string) bool
+	// ErrorMessage returns the message for the error code in the language, false if there is no translation
+	ErrorMessage(language string, code ErrorCode) (string, bool)
+	// ValidationMessage returns the message for the validation error in the language, false if there is no translation
+	ValidationMessage(language string, err ValidationError) (string, bool)
+}
+
+// MessageCatalog holds the messages of a single language keyed by error code and validation rule.
+// Validation messages are templates, {field} and the error's params (e.g. {length}) are substituted into them.
+type MessageCatalog struct {
+	// The language tag, e.g. "en" or "pt-BR"
+	Language           string
+	ErrorMessages      map[ErrorCode]string
+	ValidationMessages map[string]string
+}
+
+// CatalogLocalizer is a Localizer backed by a MessageCatalog per language.
+// A regional language such as "es-MX" falls back to the catalog of its base language "es".
+type CatalogLocalizer struct {
+	catalogs map[string]MessageCatalog
+}
+
+// NewCatalogLocalizer creates a CatalogLocalizer from the catalogs, later catalogs replace earlier ones for the same language
+func NewCatalogLocalizer(catalogs ...MessageCatalog) *CatalogLocalizer {
+	localizer := &CatalogLocalizer{catalogs: map[string]MessageCatalog{}}
+
+	for _, catalog := range catalogs {
+		localizer.catalogs[strings.ToLower(catalog.Language)] = catalog
+	}
+
+	return localizer
+}
+
+// DefaultLocalizer returns a CatalogLocalizer with the bundled English, Spanish and German catalogs
+func DefaultLocalizer() *CatalogLocalizer {
+	return NewCatalogLocalizer(EnglishMessageCatalog(), SpanishMessageCatalog(), GermanMessageCatalog())
+}
+
+// Supports reports whether there is a catalog for the language or its base language
+func (localizer *CatalogLocalizer) Supports(language string) bool {
+	_, ok := localizer.catalog(language)
+	return ok
+}
+
+// ErrorMessage returns the message for the error code in the language, false if there is no translation
+func (localizer *CatalogLocalizer) ErrorMessage(language string, code ErrorCode) (string, bool) {
+	catalog, ok := localizer.catalog(language)
+
+	if !ok {
+		return "", false
+	}
+
+	message, ok := catalog.ErrorMessages[code]
+
+	return message, ok
+}
+
+// ValidationMessage returns the message for the validation error in the language, false if there is no translation
+func (localizer *CatalogLocalizer) ValidationMessage(language string, err ValidationError) (string, bool) {
+	catalog, ok := localizer.catalog(language)
+
+	if !ok {
+		return "", false
+	}
+
+	template, ok := catalog.ValidationMessages[err.Rule]
+
+	if !ok {
+		return "", false
+	}
+
+	replacements := []string{"{field}", err.Field}
+
+	for key, value := range err.Params {
+		replacements = append(replacements, "{"+key+"}", value)
+	}
+
+	return strings.NewReplacer(replacements...).Replace(template), true
+}
+
+// catalog finds the catalog for the language, falling back to its base language
+func (localizer *CatalogLocalizer) catalog(language string) (MessageCatalog, bool) {
+	language = strings.ToLower(language)
+
+	if catalog, ok := localizer.catalogs[language]; ok {
+		return catalog, true
+	}
+
+	if base, _, found := strings.Cut(language, "-"); found {
+		catalog, ok := localizer.catalogs[base]
+		return catalog, ok
+	}
+
+	return MessageCatalog{}, false
+}
+
+// LocalizeErrorResponse returns a copy of the ErrorResponse with its message and validation errors rendered
+// in the first of the languages, in order of preference, that the localizer supports.
+// Every message is rendered in that one language, messages it has no translation for are left unchanged.
+// Usage: LocalizeErrorResponse(DefaultLocalizer(), errorResponse, RequestLanguages(r)...)
+func LocalizeErrorResponse(localizer Localizer, errorResponse *ErrorResponse, languages ...string) *ErrorResponse {
+	localized := *errorResponse
+	language, ok := negotiateLanguage(localizer, languages)
+
+	if !ok {
+		return &localized
+	}
+
+	if message, ok := localizer.ErrorMessage(language, errorResponse.Code); ok {
+		localized.Message = message
+	}
+
+	if len(errorResponse.ValidationErrors) == 0 {
+		return &localized
+	}
+
+	localized.ValidationErrors = make(ValidationErrors, len(errorResponse.ValidationErrors))
+
+	for i, err := range errorResponse.ValidationErrors {
+		if message, ok := localizer.ValidationMessage(language, err); ok {
+			err.Message = message
+		}
+
+		localized.ValidationErrors[i] = err
+	}
+
+	// The details of a validation failure are the validation messages
+	if len(errorResponse.Details) == len(errorResponse.ValidationErrors) {
+		localized.Details = localized.ValidationErrors.Messages()
+	}
+
+	return &localized
+}
+
+// negotiateLanguage returns the first of the languages the localizer supports
+func negotiateLanguage(localizer Localizer, languages []string) (string, bool) {
+	for _, language := range languages {
+		if localizer.Supports(language) {
+			return language, true
+		}
+	}
+
+	return "", false
+}
+
+// RequestLanguages returns the languages accepted by the request's Accept-Language header in order of preference
+func RequestLanguages(r *http.Request) []string {
+	return ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// ParseAcceptLanguage returns the language tags of an Accept-Language header in order of preference.
+// The wildcard and languages with a quality of zero are excluded.
+// Usage: ParseAcceptLanguage("de-CH, de;q=0.9, en;q=0.8") returns ["de-CH", "de", "en"]
+func ParseAcceptLanguage(header string) []string {
+	type weightedLanguage struct {
+		tag     string
+		quality float64
+	}
+
+	var languages []weightedLanguage
+
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		quality := 1.0
+
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+
+			if err != nil {
+				continue
+			}
+
+			quality = parsed
+		}
+
+		if tag == "" || tag == "*" || quality <= 0 {
+			continue
+		}
+
+		languages = append(languages, weightedLanguage{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	tags := make([]string, len(languages))
+
+	for i, language := range languages {
+		tags[i] = language.tag
+	}
+
+	return tags
+}
@@ -0,0 +1,57 @@
+package idam
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLocalizeErrorResponseUsesOneLanguage(t *testing.T) {
+	// The French catalog translates the error code but none of the validation rules
+	french := MessageCatalog{
+		Language:      "fr",
+		ErrorMessages: map[ErrorCode]string{RequestValidationFailure: "échec de la validation de la requête"},
+	}
+
+	localizer := NewCatalogLocalizer(french, SpanishMessageCatalog())
+	validationErrors := ValidationErrors{{Field: "email", Rule: ValidationRuleRequired, Message: "email must not be empty"}}
+
+	localized := LocalizeErrorResponse(localizer, validationErrors.ErrorResponse(), "fr-CA", "es")
+
+	if localized.Message != french.ErrorMessages[RequestValidationFailure] {
+		t.Errorf("message = %q, want the French message", localized.Message)
+	}
+
+	if got := localized.ValidationErrors[0].Message; got != "email must not be empty" {
+		t.Errorf("validation message = %q, want it left untranslated rather than rendered in Spanish", got)
+	}
+}
+
+func TestRequirePermissionUsesAuthenticateLocalizer(t *testing.T) {
+	validator := tokenValidatorFunc(func(ctx context.Context, authToken string) (*User, error) {
+		return &User{Id: "user-1"}, nil
+	})
+
+	handler := Authenticate(validator, WithLocalizer(DefaultLocalizer()))(RequirePermission("orders:write")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("next handler called for a user without the permission")
+	})))
+
+	r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	r.Header.Set("Authorization", "Bearer token-1")
+	r.Header.Set("Accept-Language", "de-DE, en;q=0.5")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, r)
+
+	var errorResponse ErrorResponse
+
+	if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := GermanMessageCatalog().ErrorMessages[AccessDenied]; w.Code != http.StatusForbidden || errorResponse.Message != want {
+		t.Errorf("response = %d %q, want %d %q", w.Code, errorResponse.Message, http.StatusForbidden, want)
+	}
+}
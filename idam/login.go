@@ -15,28 +15,48 @@ type UserLoginResponse struct {
 	UserId        string `json:"user_id"`
 	Username      string `json:"username"`
 	RefreshToken  string `json:"refresh_token"`
+	// The OpenID Connect ID token, only issued by the OAuth2 token endpoint when the openid scope is requested
+	IdToken string `json:"id_token,omitempty"`
+	// Set instead of the tokens when the user must complete a second factor, see MFARequired
+	MFAChallenge *MFAChallenge `json:"mfa_challenge,omitempty"`
+}
+
+// MFARequired reports whether the login must be completed with UserAuthClient.VerifyMFA before tokens are issued
+func (response *UserLoginResponse) MFARequired() bool {
+	return response.MFAChallenge != nil
 }
 
 func (request *UserLoginRequest) Validate() (valid bool, errors []string) {
-	// Make suer the password and username wer passed in
-	var validationErrors []string
+	return request.ValidateFields().result()
+}
 
-	pwdValResult := strval.ValidateStringWithName(request.Password, "password", strval.MustNotBeEmpty())
+// ValidateFields validates the login request, returning the errors of each field
+func (request *UserLoginRequest) ValidateFields() ValidationErrors {
+	// Make sure the password and email were passed in
+	var validationErrors ValidationErrors
 
-	if !pwdValResult.Valid {
-		validationErrors = append(validationErrors, pwdValResult.Messages...)
-	}
+	validationErrors = append(validationErrors, validateField(request.Password, "password",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
 
-	emailValResult := strval.ValidateStringWithName(request.Email, "email",
-		strval.MustNotBeEmpty(), strval.MustBeValidEmailFormat())
+	validationErrors = append(validationErrors, validateField(request.Email, "email",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+		rule(ValidationRuleEmail, strval.MustBeValidEmailFormat()))...)
 
-	if !emailValResult.Valid {
-		validationErrors = append(validationErrors, emailValResult.Messages...)
-	}
+	return validationErrors
+}
 
-	if len(validationErrors) > 0 {
-		return false, validationErrors
-	}
+// UserTokenRefreshRequest is the request object for the token refresh endpoint
+type UserTokenRefreshRequest struct {
+	// The refresh token returned by a previous login or refresh
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Validate validates the token refresh request
+func (request *UserTokenRefreshRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
 
-	return true, nil
+// ValidateFields validates the token refresh request, returning the errors of each field
+func (request *UserTokenRefreshRequest) ValidateFields() ValidationErrors {
+	return validateField(request.RefreshToken, "refresh_token", rule(ValidationRuleRequired, strval.MustNotBeEmpty()))
 }
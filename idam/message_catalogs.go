@@ -0,0 +1,147 @@
+package idam
+
+// EnglishMessageCatalog returns the bundled English catalog, matching the default messages of the library
+func EnglishMessageCatalog() MessageCatalog {
+	errorMessages := make(map[ErrorCode]string, len(errorCodeNames))
+
+	for code, entry := range errorCodeNames {
+		errorMessages[code] = entry.message
+	}
+
+	return MessageCatalog{
+		Language:      "en",
+		ErrorMessages: errorMessages,
+		ValidationMessages: map[string]string{
+			ValidationRuleRequired:             "{field} must not be empty",
+			ValidationRuleRequireOneOf:         "at least one of {fields} must be provided",
+			ValidationRuleMinLength:            "{field} must have a minimum length of {length}",
+			ValidationRuleMaxLength:            "{field} must have a maximum length of {length}",
+			ValidationRuleAlphanumeric:         "{field} must be alphanumeric",
+			ValidationRuleEmail:                "{field} must be a valid email format",
+			ValidationRuleUppercase:            "{field} must contain at least one uppercase letter",
+			ValidationRuleLowercase:            "{field} must contain at least one lowercase letter",
+			ValidationRuleNumber:               "{field} must contain numbers",
+			ValidationRuleSpecialCharacter:     "{field} must contain at least one of the following characters: {characters}",
+			ValidationRuleDisallowedCharacters: "{field} must not contain any of the following characters: {characters}",
+			ValidationRulePrintable:            "{field} must only contain printable characters",
+			ValidationRuleASCII:                "{field} must only contain ASCII characters",
+			ValidationRuleStrength:             "{field} is too easy to guess",
+			ValidationRuleBreached:             "{field} has appeared in a data breach and must not be used",
+			ValidationRuleBreachCheckFailed:    "{field} could not be checked for data breaches",
+			ValidationRuleNotSameAs:            "{field} must not be the same as {other}",
+			ValidationRuleAbsoluteURL:          "{field} must only contain absolute urls without a fragment: {value}",
+			ValidationRuleNonNegative:          "{field} must not be negative",
+			ValidationRuleNotShorterThan:       "{field} must not be shorter than {other}",
+			ValidationRuleOneOf:                "{field} must be one of: {values}",
+			ValidationRuleNotOneOf:             "{field} must not be one of: {values}",
+		},
+	}
+}
+
+// SpanishMessageCatalog returns the bundled Spanish catalog
+func SpanishMessageCatalog() MessageCatalog {
+	return MessageCatalog{
+		Language: "es",
+		ErrorMessages: map[ErrorCode]string{
+			UnhandledError:                       "se produjo un error inesperado",
+			RequestPayloadInvalid:                "no se pudo procesar el cuerpo de la solicitud",
+			RequestValidationFailure:             "la validación de la solicitud falló",
+			ApplicationNotFound:                  "aplicación no encontrada",
+			InvalidCredentials:                   "credenciales no válidas",
+			DataConflict:                         "conflicto de datos",
+			UserNotVerified:                      "usuario no verificado",
+			InvalidAuthToken:                     "token de autorización no válido o mal formado",
+			AccessDenied:                         "acceso denegado",
+			InvalidUserVerficationToken:          "código de verificación no válido",
+			UserNotFound:                         "usuario no encontrado",
+			InvalidPasswordResetToken:            "token de restablecimiento de contraseña no válido",
+			InvalidPasswordResetVerificationCode: "código de verificación de restablecimiento de contraseña no válido",
+			InvalidRequestHeaders:                "encabezados de solicitud no válidos o ausentes",
+			AuthTokenExpired:                     "el token de autorización ha caducado",
+			UserAccountLockout:                   "cuenta de usuario bloqueada por demasiados intentos fallidos de inicio de sesión",
+			InvalidMFACode:                       "código de autenticación multifactor no válido",
+			InvalidMFAChallenge:                  "desafío de autenticación multifactor no válido o caducado",
+			ExternalProviderNotFound:             "proveedor de identidad externo no encontrado",
+			InvalidExternalAuthorization:         "autorización del proveedor de identidad externo no válida",
+			SessionNotFound:                      "sesión no encontrada",
+		},
+		ValidationMessages: map[string]string{
+			ValidationRuleRequired:             "{field} no debe estar vacío",
+			ValidationRuleRequireOneOf:         "se debe proporcionar al menos uno de {fields}",
+			ValidationRuleMinLength:            "{field} debe tener una longitud mínima de {length}",
+			ValidationRuleMaxLength:            "{field} debe tener una longitud máxima de {length}",
+			ValidationRuleAlphanumeric:         "{field} debe ser alfanumérico",
+			ValidationRuleEmail:                "{field} debe tener un formato de correo electrónico válido",
+			ValidationRuleUppercase:            "{field} debe contener al menos una letra mayúscula",
+			ValidationRuleLowercase:            "{field} debe contener al menos una letra minúscula",
+			ValidationRuleNumber:               "{field} debe contener números",
+			ValidationRuleSpecialCharacter:     "{field} debe contener al menos uno de los siguientes caracteres: {characters}",
+			ValidationRuleDisallowedCharacters: "{field} no debe contener ninguno de los siguientes caracteres: {characters}",
+			ValidationRulePrintable:            "{field} solo debe contener caracteres imprimibles",
+			ValidationRuleASCII:                "{field} solo debe contener caracteres ASCII",
+			ValidationRuleStrength:             "{field} es demasiado fácil de adivinar",
+			ValidationRuleBreached:             "{field} ha aparecido en una filtración de datos y no debe usarse",
+			ValidationRuleBreachCheckFailed:    "no se pudo comprobar si {field} aparece en filtraciones de datos",
+			ValidationRuleNotSameAs:            "{field} no debe ser igual a {other}",
+			ValidationRuleAbsoluteURL:          "{field} solo debe contener URL absolutas sin fragmento: {value}",
+			ValidationRuleNonNegative:          "{field} no debe ser negativo",
+			ValidationRuleNotShorterThan:       "{field} no debe ser más corto que {other}",
+			ValidationRuleOneOf:                "{field} debe ser uno de: {values}",
+			ValidationRuleNotOneOf:             "{field} no debe ser ninguno de: {values}",
+		},
+	}
+}
+
+// GermanMessageCatalog returns the bundled German catalog
+func GermanMessageCatalog() MessageCatalog {
+	return MessageCatalog{
+		Language: "de",
+		ErrorMessages: map[ErrorCode]string{
+			UnhandledError:                       "ein unerwarteter Fehler ist aufgetreten",
+			RequestPayloadInvalid:                "der Inhalt der Anfrage konnte nicht verarbeitet werden",
+			RequestValidationFailure:             "die Validierung der Anfrage ist fehlgeschlagen",
+			ApplicationNotFound:                  "Anwendung nicht gefunden",
+			InvalidCredentials:                   "ungültige Anmeldedaten",
+			DataConflict:                         "Datenkonflikt",
+			UserNotVerified:                      "Benutzer nicht verifiziert",
+			InvalidAuthToken:                     "ungültiges oder fehlerhaftes Autorisierungstoken",
+			AccessDenied:                         "Zugriff verweigert",
+			InvalidUserVerficationToken:          "ungültiger Verifizierungscode",
+			UserNotFound:                         "Benutzer nicht gefunden",
+			InvalidPasswordResetToken:            "ungültiges Token zum Zurücksetzen des Passworts",
+			InvalidPasswordResetVerificationCode: "ungültiger Verifizierungscode zum Zurücksetzen des Passworts",
+			InvalidRequestHeaders:                "ungültige oder fehlende Anfrage-Header",
+			AuthTokenExpired:                     "Autorisierungstoken abgelaufen",
+			UserAccountLockout:                   "Benutzerkonto wegen zu vieler fehlgeschlagener Anmeldeversuche gesperrt",
+			InvalidMFACode:                       "ungültiger Code für die Multi-Faktor-Authentifizierung",
+			InvalidMFAChallenge:                  "ungültige oder abgelaufene Multi-Faktor-Authentifizierungsanfrage",
+			ExternalProviderNotFound:             "externer Identitätsanbieter nicht gefunden",
+			InvalidExternalAuthorization:         "ungültige Autorisierung des externen Identitätsanbieters",
+			SessionNotFound:                      "Sitzung nicht gefunden",
+		},
+		ValidationMessages: map[string]string{
+			ValidationRuleRequired:             "{field} darf nicht leer sein",
+			ValidationRuleRequireOneOf:         "mindestens eines von {fields} muss angegeben werden",
+			ValidationRuleMinLength:            "{field} muss mindestens {length} Zeichen lang sein",
+			ValidationRuleMaxLength:            "{field} darf höchstens {length} Zeichen lang sein",
+			ValidationRuleAlphanumeric:         "{field} darf nur Buchstaben und Ziffern enthalten",
+			ValidationRuleEmail:                "{field} muss eine gültige E-Mail-Adresse sein",
+			ValidationRuleUppercase:            "{field} muss mindestens einen Großbuchstaben enthalten",
+			ValidationRuleLowercase:            "{field} muss mindestens einen Kleinbuchstaben enthalten",
+			ValidationRuleNumber:               "{field} muss Ziffern enthalten",
+			ValidationRuleSpecialCharacter:     "{field} muss mindestens eines der folgenden Zeichen enthalten: {characters}",
+			ValidationRuleDisallowedCharacters: "{field} darf keines der folgenden Zeichen enthalten: {characters}",
+			ValidationRulePrintable:            "{field} darf nur druckbare Zeichen enthalten",
+			ValidationRuleASCII:                "{field} darf nur ASCII-Zeichen enthalten",
+			ValidationRuleStrength:             "{field} ist zu leicht zu erraten",
+			ValidationRuleBreached:             "{field} ist in einem Datenleck aufgetaucht und darf nicht verwendet werden",
+			ValidationRuleBreachCheckFailed:    "{field} konnte nicht auf Datenlecks geprüft werden",
+			ValidationRuleNotSameAs:            "{field} darf nicht mit {other} übereinstimmen",
+			ValidationRuleAbsoluteURL:          "{field} darf nur absolute URLs ohne Fragment enthalten: {value}",
+			ValidationRuleNonNegative:          "{field} darf nicht negativ sein",
+			ValidationRuleNotShorterThan:       "{field} darf nicht kürzer als {other} sein",
+			ValidationRuleOneOf:                "{field} muss einer der folgenden Werte sein: {values}",
+			ValidationRuleNotOneOf:             "{field} darf keiner der folgenden Werte sein: {values}",
+		},
+	}
+}
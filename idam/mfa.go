@@ -0,0 +1,73 @@
+package idam
+
+import (
+	"time"
+
+	"github.com/dmars8047/strval"
+)
+
+// The second factors that can complete an MFAChallenge
+const (
+	MFAMethodTOTP         = "totp"
+	MFAMethodRecoveryCode = "recovery_code"
+)
+
+// MFAChallenge is returned in place of tokens by the login endpoint when the user has multi-factor authentication enabled.
+// The login is completed by verifying a code against the challenge with UserAuthClient.VerifyMFA.
+type MFAChallenge struct {
+	ChallengeId string `json:"challenge_id"`
+	// The second factors the challenge accepts, e.g. MFAMethodTOTP
+	Methods      []string  `json:"methods"`
+	ExpiresAtUTC time.Time `json:"expires_at_utc"`
+}
+
+// MFAVerificationRequest is the request object for the MFA verification endpoint
+type MFAVerificationRequest struct {
+	ChallengeId string `json:"challenge_id"`
+	// The TOTP code from the user's authenticator app or one of their unused recovery codes
+	Code string `json:"code"`
+}
+
+// Validate validates the MFA verification request
+func (request *MFAVerificationRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the MFA verification request, returning the errors of each field
+func (request *MFAVerificationRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateField(request.ChallengeId, "challenge_id",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.Code, "code",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	return validationErrors
+}
+
+// TOTPEnrollment is the response object of the TOTP enrollment endpoint.
+// The secret is not active until the enrollment is confirmed with a code generated from it.
+type TOTPEnrollment struct {
+	// The base32 encoded secret, for users who enter it into their authenticator app by hand
+	Secret string `json:"secret"`
+	// The otpauth:// URI to show as a QR code
+	OTPAuthURI string `json:"otpauth_uri"`
+	// Single use codes that complete an MFAChallenge when the authenticator is unavailable, only returned once
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFACodeRequest is the request object for the endpoints confirming or disabling TOTP enrollment
+type MFACodeRequest struct {
+	Code string `json:"code"`
+}
+
+// Validate validates the MFA code request
+func (request *MFACodeRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the MFA code request, returning the errors of each field
+func (request *MFACodeRequest) ValidateFields() ValidationErrors {
+	return validateField(request.Code, "code", rule(ValidationRuleRequired, strval.MustNotBeEmpty()))
+}
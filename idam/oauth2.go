@@ -0,0 +1,302 @@
+package idam
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	OAuth2AuthorizationUrlSuffix = "/api/idam/oauth2/authorize"
+	OAuth2TokenUrlSuffix         = "/api/idam/oauth2/token"
+)
+
+// OAuth2Config describes a public OAuth2 client, such as a single page or mobile app, using IDAM as its authorization server
+type OAuth2Config struct {
+	// The client id, which is the IDAM application id
+	ClientId string
+	// Where IDAM redirects the user back to, it must be one of the application's allowed redirect uris
+	RedirectURI string
+	Scopes      []string
+}
+
+// AuthorizationRequest is a started authorization code flow.
+// The State, Nonce and PKCE verifier are secrets that must be kept (e.g. in session storage) until IDAM redirects back.
+type AuthorizationRequest struct {
+	// The url to send the user to
+	URL         string
+	ClientId    string
+	RedirectURI string
+	State       string
+	// The nonce the ID token issued for the request must contain
+	Nonce string
+	PKCE  *PKCE
+}
+
+// OAuth2Error is an RFC 6749 error returned by the token endpoint or the authorization redirect
+type OAuth2Error struct {
+	// The error code, e.g. "invalid_grant"
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+	// The http status code of the token endpoint response, zero for errors from the authorization redirect
+	StatusCode int `json:"-"`
+}
+
+// Error returns the error code and description
+func (err *OAuth2Error) Error() string {
+	if err.Description == "" {
+		return "oauth2 error " + err.Code
+	}
+
+	return "oauth2 error " + err.Code + ": " + err.Description
+}
+
+// decodeOAuth2Error returns the OAuth2Error in the response body, nil if it is not one
+func decodeOAuth2Error(statusCode int, body []byte) *OAuth2Error {
+	var oauth2Err OAuth2Error
+
+	if err := json.Unmarshal(body, &oauth2Err); err != nil || oauth2Err.Code == "" {
+		return nil
+	}
+
+	oauth2Err.StatusCode = statusCode
+
+	return &oauth2Err
+}
+
+// oauth2TokenResponse is the RFC 6749 token endpoint response
+type oauth2TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	IdToken      string `json:"id_token"`
+}
+
+// AuthorizationRequest starts an authorization code flow with PKCE, generating the state, nonce and code verifier.
+// The user should be sent to the returned request's URL.
+func (client *UserAuthClient) AuthorizationRequest(config OAuth2Config) (*AuthorizationRequest, error) {
+	authorizationUrl, err := client.executor.resolveUrl(OAuth2AuthorizationUrlSuffix)
+
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := GenerateState()
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := GenerateState()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pkce, err := GeneratePKCE()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", config.ClientId)
+	query.Set("redirect_uri", config.RedirectURI)
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", pkce.Challenge)
+	query.Set("code_challenge_method", pkce.Method)
+
+	if len(config.Scopes) > 0 {
+		query.Set("scope", strings.Join(config.Scopes, " "))
+	}
+
+	return &AuthorizationRequest{
+		URL:         authorizationUrl + "?" + query.Encode(),
+		ClientId:    config.ClientId,
+		RedirectURI: config.RedirectURI,
+		State:       state,
+		Nonce:       nonce,
+		PKCE:        pkce,
+	}, nil
+}
+
+// ExchangeAuthorizationCode completes the authorization request with the query parameters IDAM redirected back with.
+// The state is checked before the code is exchanged at the token endpoint, an error is returned if the request has no state or PKCE verifier.
+// If an ID token is issued it is verified against the request's nonce, an *ErrorResponse is returned if it is rejected.
+// An *OAuth2Error is returned if the authorization was denied or the exchange failed.
+// Usage: login, err := client.ExchangeAuthorizationCode(request, r.URL.Query())
+func (client *UserAuthClient) ExchangeAuthorizationCode(request *AuthorizationRequest, callbackQuery url.Values) (*UserLoginResponse, error) {
+	return client.ExchangeAuthorizationCodeContext(context.Background(), request, callbackQuery)
+}
+
+// ExchangeAuthorizationCodeContext completes the authorization request using the provided context
+func (client *UserAuthClient) ExchangeAuthorizationCodeContext(ctx context.Context, request *AuthorizationRequest, callbackQuery url.Values) (*UserLoginResponse, error) {
+	if request == nil || request.PKCE == nil || request.PKCE.Verifier == "" {
+		return nil, errors.New("authorization request must have a pkce code verifier, create it with AuthorizationRequest")
+	}
+
+	// Two empty states would compare equal, skipping the CSRF check
+	if request.State == "" {
+		return nil, errors.New("authorization request must have a state, create it with AuthorizationRequest")
+	}
+
+	if code := callbackQuery.Get("error"); code != "" {
+		return nil, &OAuth2Error{Code: code, Description: callbackQuery.Get("error_description")}
+	}
+
+	state := callbackQuery.Get("state")
+
+	if state == "" {
+		return nil, &OAuth2Error{Code: "invalid_request", Description: "missing state"}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(state), []byte(request.State)) != 1 {
+		return nil, &OAuth2Error{Code: "invalid_request", Description: "state does not match"}
+	}
+
+	if callbackQuery.Get("code") == "" {
+		return nil, &OAuth2Error{Code: "invalid_request", Description: "missing authorization code"}
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", callbackQuery.Get("code"))
+	form.Set("redirect_uri", request.RedirectURI)
+	form.Set("client_id", request.ClientId)
+	form.Set("code_verifier", request.PKCE.Verifier)
+
+	login, err := client.oauth2Token(ctx, request.ClientId, form, "exchange authorization code")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if login.IdToken != "" {
+		verifier, err := client.TokenVerifier(request.ClientId)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = request.VerifyIDToken(ctx, verifier, login.IdToken); err != nil {
+			return nil, err
+		}
+	}
+
+	return login, nil
+}
+
+// VerifyIDToken verifies an ID token issued for the request, checking it carries the request's nonce.
+// ID tokens issued when the token is refreshed carry the nonce of the original request.
+func (request *AuthorizationRequest) VerifyIDToken(ctx context.Context, verifier *TokenVerifier, idToken string) (*IDTokenClaims, error) {
+	return verifier.VerifyIDToken(ctx, idToken, request.Nonce)
+}
+
+// RefreshOAuth2Token exchanges an OAuth2 refresh token for a new token at the token endpoint
+func (client *UserAuthClient) RefreshOAuth2Token(clientId string, refreshToken string) (*UserLoginResponse, error) {
+	return client.RefreshOAuth2TokenContext(context.Background(), clientId, refreshToken)
+}
+
+// RefreshOAuth2TokenContext exchanges an OAuth2 refresh token for a new token using the provided context
+func (client *UserAuthClient) RefreshOAuth2TokenContext(ctx context.Context, clientId string, refreshToken string) (*UserLoginResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+	form.Set("client_id", clientId)
+
+	return client.oauth2Token(ctx, clientId, form, "refresh oauth2 token")
+}
+
+// oauth2Token calls the token endpoint, returning the token in the same shape as a login
+func (client *UserAuthClient) oauth2Token(ctx context.Context, clientId string, form url.Values, operation string) (*UserLoginResponse, error) {
+	var tokenResponse oauth2TokenResponse
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      OAuth2TokenUrlSuffix,
+		form:           form,
+		expectedStatus: http.StatusOK,
+		response:       &tokenResponse,
+		operation:      operation,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserLoginResponse{
+		Token:         tokenResponse.AccessToken,
+		TokenType:     tokenResponse.TokenType,
+		ApplicationId: clientId,
+		ExpiresIn:     tokenResponse.ExpiresIn,
+		RefreshToken:  tokenResponse.RefreshToken,
+		IdToken:       tokenResponse.IdToken,
+	}, nil
+}
+
+// NewOAuth2TokenSource creates a TokenSource seeded with the token from an authorization code exchange,
+// which is refreshed at the OAuth2 token endpoint rather than the user account refresh endpoint.
+// An error is returned if the response has no access token or refresh token.
+func NewOAuth2TokenSource(client *UserAuthClient, clientId string, login *UserLoginResponse) (*TokenSource, error) {
+	source, err := NewTokenSource(client, clientId, login)
+
+	if err != nil {
+		return nil, err
+	}
+
+	source.refreshFunc = client.RefreshOAuth2TokenContext
+
+	return source, nil
+}
+
+// OAuth2 adapts the TokenSource to an oauth2.TokenSource, so it can be used with oauth2.NewClient.
+// The context is used for the refresh calls.
+func (source *TokenSource) OAuth2(ctx context.Context) oauth2.TokenSource {
+	return oauth2TokenSource{ctx: ctx, source: source}
+}
+
+// oauth2TokenSource adapts a TokenSource to an oauth2.TokenSource
+type oauth2TokenSource struct {
+	ctx    context.Context
+	source *TokenSource
+}
+
+// Token returns the source's current token as an oauth2.Token
+func (adapter oauth2TokenSource) Token() (*oauth2.Token, error) {
+	response, expiresAt, err := adapter.source.token(adapter.ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  response.Token,
+		TokenType:    response.TokenType,
+		RefreshToken: response.RefreshToken,
+		Expiry:       expiresAt,
+	}
+
+	if response.IdToken != "" {
+		token = token.WithExtra(map[string]any{"id_token": response.IdToken})
+	}
+
+	return token, nil
+}
+
+// Compile time check that the adapter satisfies oauth2.TokenSource
+var _ oauth2.TokenSource = oauth2TokenSource{}
+
+// expiry returns when a token issued now with the lifetime in seconds expires
+func expiry(expiresIn int64) time.Time {
+	return time.Now().Add(time.Duration(expiresIn) * time.Second)
+}
@@ -0,0 +1,104 @@
+package idam
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestExchangeAuthorizationCodeRejectsInvalidRequests(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	client := newTestClient(t, server)
+
+	pkce := &PKCE{Verifier: rfc7636Verifier, Challenge: rfc7636Challenge, Method: PKCEMethodS256}
+	callbackQuery := url.Values{"code": {"code-1"}, "state": {"state-1"}}
+
+	tests := []struct {
+		name    string
+		request *AuthorizationRequest
+	}{
+		{"nil request", nil},
+		{"nil pkce", &AuthorizationRequest{ClientId: "app", State: "state-1"}},
+		{"empty verifier", &AuthorizationRequest{ClientId: "app", State: "state-1", PKCE: &PKCE{Challenge: rfc7636Challenge}}},
+		{"empty state", &AuthorizationRequest{ClientId: "app", PKCE: pkce}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := client.ExchangeAuthorizationCode(tt.request, callbackQuery); err == nil {
+				t.Error("ExchangeAuthorizationCode returned no error")
+			}
+		})
+	}
+
+	if got := server.requestCount(); got != 0 {
+		t.Errorf("requests = %d, want none", got)
+	}
+}
+
+func TestExchangeAuthorizationCodeChecksTheState(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	client := newTestClient(t, server)
+
+	request := &AuthorizationRequest{
+		ClientId: "app",
+		State:    "state-1",
+		PKCE:     &PKCE{Verifier: rfc7636Verifier, Challenge: rfc7636Challenge, Method: PKCEMethodS256},
+	}
+
+	tests := []struct {
+		name          string
+		callbackQuery url.Values
+	}{
+		{"no state", url.Values{"code": {"code-1"}}},
+		{"empty state", url.Values{"code": {"code-1"}, "state": {""}}},
+		{"other state", url.Values{"code": {"code-1"}, "state": {"state-2"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var oauth2Err *OAuth2Error
+
+			if _, err := client.ExchangeAuthorizationCode(request, tt.callbackQuery); !errors.As(err, &oauth2Err) || oauth2Err.Code != "invalid_request" {
+				t.Errorf("error = %v, want an invalid_request OAuth2Error", err)
+			}
+		})
+	}
+
+	if got := server.requestCount(); got != 0 {
+		t.Errorf("requests = %d, want none", got)
+	}
+}
+
+func TestExchangeAuthorizationCodeSendsTheVerifier(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{"access_token":"token-1","token_type":"Bearer","expires_in":3600}`))
+	client := newTestClient(t, server)
+
+	request := &AuthorizationRequest{
+		ClientId:    "app",
+		RedirectURI: "https://app.example.com/callback",
+		State:       "state-1",
+		PKCE:        &PKCE{Verifier: rfc7636Verifier, Challenge: rfc7636Challenge, Method: PKCEMethodS256},
+	}
+
+	login, err := client.ExchangeAuthorizationCode(request, url.Values{"code": {"code-1"}, "state": {"state-1"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if login.Token != "token-1" {
+		t.Errorf("token = %q, want %q", login.Token, "token-1")
+	}
+
+	form, err := url.ParseQuery(server.lastRequest(t).body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if form.Get("code_verifier") != rfc7636Verifier || form.Get("code") != "code-1" || form.Get("grant_type") != "authorization_code" {
+		t.Errorf("token request form = %v, want the code and verifier of the authorization", form)
+	}
+}
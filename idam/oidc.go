@@ -0,0 +1,268 @@
+package idam
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// OIDCDiscoveryUrlSuffix is the url suffix of the IDAM service's OpenID Connect discovery document
+const OIDCDiscoveryUrlSuffix = "/.well-known/openid-configuration"
+
+const (
+	// ScopeOpenID requests an ID token, making the authorization request an OpenID Connect request
+	ScopeOpenID = "openid"
+	// ScopeEmail requests the email and email_verified claims
+	ScopeEmail = "email"
+	// ScopeProfile requests the preferred_username claim
+	ScopeProfile = "profile"
+)
+
+// ProviderMetadata is the OpenID Connect discovery document of the IDAM service
+type ProviderMetadata struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserInfoEndpoint                  string   `json:"userinfo_endpoint,omitempty"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ScopesSupported                   []string `json:"scopes_supported,omitempty"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported,omitempty"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported,omitempty"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported,omitempty"`
+	ClaimsSupported                   []string `json:"claims_supported,omitempty"`
+}
+
+// SupportsScope reports whether the scope is listed in the supported scopes.
+// A provider that does not list its scopes is assumed to support the scope.
+func (metadata *ProviderMetadata) SupportsScope(scope string) bool {
+	return len(metadata.ScopesSupported) == 0 || slices.Contains(metadata.ScopesSupported, scope)
+}
+
+// validate checks the issuer is set and the discovered endpoints are absolute urls
+func (metadata *ProviderMetadata) validate() error {
+	if metadata.Issuer == "" {
+		return errors.New("provider metadata has no issuer")
+	}
+
+	for name, endpoint := range map[string]string{
+		"issuer":                 metadata.Issuer,
+		"authorization_endpoint": metadata.AuthorizationEndpoint,
+		"token_endpoint":         metadata.TokenEndpoint,
+		"jwks_uri":               metadata.JWKSURI,
+	} {
+		if endpoint == "" {
+			continue
+		}
+
+		if parsed, err := url.Parse(endpoint); err != nil || !parsed.IsAbs() || parsed.Host == "" {
+			return fmt.Errorf("provider metadata %s %q is not an absolute url", name, endpoint)
+		}
+	}
+
+	return nil
+}
+
+// endpoints returns the discovered endpoint urls keyed by the url suffix they replace, nil if there is no metadata
+func (metadata *ProviderMetadata) endpoints() map[string]string {
+	if metadata == nil {
+		return nil
+	}
+
+	endpoints := map[string]string{}
+
+	for urlSuffix, endpoint := range map[string]string{
+		OAuth2AuthorizationUrlSuffix: metadata.AuthorizationEndpoint,
+		OAuth2TokenUrlSuffix:         metadata.TokenEndpoint,
+		JWKSUrlSuffix:                metadata.JWKSURI,
+	} {
+		if endpoint != "" {
+			endpoints[urlSuffix] = endpoint
+		}
+	}
+
+	return endpoints
+}
+
+// WithProviderMetadata uses the endpoints of the OpenID Connect discovery document instead of the url suffix constants.
+// Usually Discover is used instead, which fetches the document from the IDAM service.
+func WithProviderMetadata(metadata *ProviderMetadata) ClientOption {
+	return func(options *clientOptions) error {
+		if metadata == nil {
+			return errors.New("provider metadata must not be nil")
+		}
+
+		if err := metadata.validate(); err != nil {
+			return err
+		}
+
+		options.metadata = metadata
+
+		return nil
+	}
+}
+
+// Discover creates a UserAuthClient for the IDAM service at the issuerUrl configured with the options,
+// bootstrapping its OAuth2 and signing key endpoints from the service's OpenID Connect discovery document.
+// An error is returned if the document cannot be fetched or its issuer is not the issuerUrl.
+// Usage: Discover(ctx, "https://idam.example.com", WithTimeout(10*time.Second))
+func Discover(ctx context.Context, issuerUrl string, opts ...ClientOption) (*UserAuthClient, error) {
+	client, err := New(issuerUrl, opts...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata ProviderMetadata
+
+	err = client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      OIDCDiscoveryUrlSuffix,
+		expectedStatus: http.StatusOK,
+		response:       &metadata,
+		operation:      "discover provider metadata",
+		retryable:      true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(issuerUrl, "/") {
+		return nil, fmt.Errorf("provider metadata issuer %q does not match %q", metadata.Issuer, issuerUrl)
+	}
+
+	if err = metadata.validate(); err != nil {
+		return nil, err
+	}
+
+	client.metadata = &metadata
+	client.executor.endpoints = metadata.endpoints()
+
+	return client, nil
+}
+
+// ProviderMetadata returns the discovery document the client was configured with, nil if it was not
+func (client *UserAuthClient) ProviderMetadata() *ProviderMetadata {
+	return client.metadata
+}
+
+// TokenVerifier returns a TokenVerifier for the access and ID tokens issued for the application (the OAuth2 client id),
+// fetching signing keys through the client's http client and middleware.
+// The issuer is the discovered issuer, or the base url if the client was not configured with provider metadata.
+// An error is returned if the appId is empty and the client has no default app id.
+func (client *UserAuthClient) TokenVerifier(appId string) (*TokenVerifier, error) {
+	keys, err := client.keySet()
+
+	if err != nil {
+		return nil, err
+	}
+
+	issuer := strings.TrimSuffix(client.executor.base.String(), "/")
+
+	if client.metadata != nil {
+		issuer = client.metadata.Issuer
+	}
+
+	if appId == "" {
+		appId = client.executor.defaultAppId
+	}
+
+	return NewTokenVerifier(keys, issuer, appId)
+}
+
+// keySet returns the signing key set of the IDAM service, which is created on first use and shared by the client's verifiers
+func (client *UserAuthClient) keySet() (*JWKSKeySet, error) {
+	client.keysMu.Lock()
+	defer client.keysMu.Unlock()
+
+	if client.keys == nil {
+		jwksUrl, err := client.executor.resolveUrl(JWKSUrlSuffix)
+
+		if err != nil {
+			return nil, err
+		}
+
+		client.keys = &JWKSKeySet{
+			doer:     client.executor.doer,
+			jwksUrl:  jwksUrl,
+			cacheTTL: DefaultJWKSCacheTTL,
+		}
+	}
+
+	return client.keys, nil
+}
+
+// IDTokenClaims are the claims carried by an OpenID Connect ID token issued by the IDAM service
+type IDTokenClaims struct {
+	Issuer            string   `json:"iss"`
+	Subject           string   `json:"sub"`
+	Audience          Audience `json:"aud"`
+	ExpiresAt         int64    `json:"exp"`
+	NotBefore         int64    `json:"nbf,omitempty"`
+	IssuedAt          int64    `json:"iat"`
+	AuthTime          int64    `json:"auth_time,omitempty"`
+	Nonce             string   `json:"nonce,omitempty"`
+	AuthorizedParty   string   `json:"azp,omitempty"`
+	Email             string   `json:"email,omitempty"`
+	EmailVerified     bool     `json:"email_verified,omitempty"`
+	PreferredUsername string   `json:"preferred_username,omitempty"`
+}
+
+// User returns the User described by the claims.
+// Only the subject is always present, the other fields depend on the scopes that were granted.
+func (claims *IDTokenClaims) User() *User {
+	return &User{
+		Id:       claims.Subject,
+		Username: claims.PreferredUsername,
+		Email:    claims.Email,
+		Verified: claims.EmailVerified,
+		Type:     StandardUserType,
+	}
+}
+
+// VerifyIDToken checks the ID token's type, signature, expiry, issuer, audience and nonce and returns its claims.
+// The nonce must be the one sent in the authorization request and is required, a token without it is rejected.
+// An *ErrorResponse with the InvalidAuthToken or AuthTokenExpired code is returned if the token is rejected.
+// Usage: claims, err := verifier.VerifyIDToken(ctx, login.IdToken, request.Nonce)
+func (verifier *TokenVerifier) VerifyIDToken(ctx context.Context, idToken string, nonce string) (*IDTokenClaims, error) {
+	if nonce == "" {
+		return nil, errors.New("id token nonce must not be empty, it is the Nonce of the AuthorizationRequest")
+	}
+
+	if idToken == "" {
+		return nil, invalidAuthToken("no id token was issued, the openid scope must be requested")
+	}
+
+	var claims IDTokenClaims
+
+	if err := verifyJWT(ctx, verifier.keys, idToken, idTokenJWT, &claims); err != nil {
+		return nil, err
+	}
+
+	if err := verifier.checkClaims(claims.Issuer, claims.Audience, claims.ExpiresAt, claims.NotBefore); err != nil {
+		return nil, err
+	}
+
+	if claims.Subject == "" || claims.IssuedAt == 0 {
+		return nil, invalidAuthToken("id token is missing required claims")
+	}
+
+	// A token issued for several audiences must name the application as the party it was issued to
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != verifier.applicationId {
+		return nil, invalidAuthToken("id token authorized party is invalid")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, invalidAuthToken("id token nonce is invalid")
+	}
+
+	return &claims, nil
+}
@@ -0,0 +1,164 @@
+package idam
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"sync"
+)
+
+// BreachedPasswordChecker checks whether a password is known to have appeared in a data breach
+type BreachedPasswordChecker interface {
+	// IsBreached reports whether the password has appeared in a data breach
+	IsBreached(ctx context.Context, password string) (bool, error)
+}
+
+const (
+	// The number of password hashes a zero value BloomFilterBreachChecker is sized to hold
+	defaultBloomFilterHashes = 1_000_000
+	// The rate a BloomFilterBreachChecker falsely reports passwords as breached at, unless another valid rate is given
+	defaultBloomFilterFalsePositiveRate = 0.001
+)
+
+// BloomFilterBreachChecker is an offline BreachedPasswordChecker backed by a bloom filter of SHA-1 password hashes.
+// A bloom filter never misses a breached password but reports a small fraction of other passwords as breached,
+// in exchange for using a fraction of the memory of the full hash list.
+// The zero value is an empty checker sized for a million hashes when the first one is added,
+// use NewBloomFilterBreachChecker to size it for the hash list. It is safe for concurrent use.
+type BloomFilterBreachChecker struct {
+	mu        sync.RWMutex
+	bits      []uint64
+	numBits   uint64
+	numHashes int
+}
+
+// NewBloomFilterBreachChecker creates an empty BloomFilterBreachChecker sized to hold expectedHashes password hashes
+// while falsely reporting passwords as breached at the falsePositiveRate.
+// Usage: NewBloomFilterBreachChecker(1_000_000, 0.001)
+func NewBloomFilterBreachChecker(expectedHashes int, falsePositiveRate float64) *BloomFilterBreachChecker {
+	checker := &BloomFilterBreachChecker{}
+	checker.size(expectedHashes, falsePositiveRate)
+
+	return checker
+}
+
+// size allocates the filter's bits for the expected number of hashes and false positive rate
+func (checker *BloomFilterBreachChecker) size(expectedHashes int, falsePositiveRate float64) {
+	expectedHashes = max(expectedHashes, 1)
+
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		falsePositiveRate = defaultBloomFilterFalsePositiveRate
+	}
+
+	numBits := uint64(math.Ceil(-float64(expectedHashes) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2)))
+	numHashes := int(math.Round(float64(numBits) / float64(expectedHashes) * math.Ln2))
+
+	checker.bits = make([]uint64, (numBits+63)/64)
+	checker.numBits = numBits
+	checker.numHashes = max(numHashes, 1)
+}
+
+// AddPassword adds the password to the set of breached passwords
+func (checker *BloomFilterBreachChecker) AddPassword(password string) {
+	hash := sha1.Sum([]byte(password))
+	checker.add(hash)
+}
+
+// AddHash adds the hex encoded SHA-1 hash of a password to the set of breached passwords
+func (checker *BloomFilterBreachChecker) AddHash(sha1Hex string) error {
+	var hash [sha1.Size]byte
+
+	if n, err := hex.Decode(hash[:], []byte(sha1Hex)); err != nil || n != sha1.Size {
+		return fmt.Errorf("invalid SHA-1 password hash %q", sha1Hex)
+	}
+
+	checker.add(hash)
+
+	return nil
+}
+
+// LoadHashList adds every hash in the list to the set of breached passwords.
+// The list has one hex encoded SHA-1 hash per line, optionally followed by a colon and a count,
+// which is the format of the Have I Been Pwned password downloads. Blank lines are ignored.
+func (checker *BloomFilterBreachChecker) LoadHashList(reader io.Reader) error {
+	scanner := bufio.NewScanner(reader)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" {
+			continue
+		}
+
+		sha1Hex, _, _ := strings.Cut(line, ":")
+
+		if err := checker.AddHash(sha1Hex); err != nil {
+			return fmt.Errorf("hash list line %d: %w", lineNumber, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// IsBreached reports whether the password may have appeared in a data breach
+func (checker *BloomFilterBreachChecker) IsBreached(ctx context.Context, password string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	hash := sha1.Sum([]byte(password))
+
+	checker.mu.RLock()
+	defer checker.mu.RUnlock()
+
+	// A zero value checker no hash has been added to is empty
+	if checker.numBits == 0 {
+		return false, nil
+	}
+
+	for _, bit := range checker.bitIndexes(hash) {
+		if checker.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// add sets the bits of the hash, sizing the filter of a zero value checker first
+func (checker *BloomFilterBreachChecker) add(hash [sha1.Size]byte) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+
+	if checker.numBits == 0 {
+		checker.size(defaultBloomFilterHashes, defaultBloomFilterFalsePositiveRate)
+	}
+
+	for _, bit := range checker.bitIndexes(hash) {
+		checker.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// bitIndexes derives the filter's bit positions for the hash.
+// SHA-1 output is uniformly distributed so two halves of it are combined by double hashing rather than rehashing.
+func (checker *BloomFilterBreachChecker) bitIndexes(hash [sha1.Size]byte) []uint64 {
+	h1 := binary.BigEndian.Uint64(hash[0:8])
+	h2 := binary.BigEndian.Uint64(hash[8:16]) | 1
+
+	indexes := make([]uint64, checker.numHashes)
+
+	for i := range indexes {
+		indexes[i] = (h1 + uint64(i)*h2) % checker.numBits
+	}
+
+	return indexes
+}
@@ -0,0 +1,71 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestBloomFilterBreachChecker(t *testing.T) {
+	tests := []struct {
+		name    string
+		checker *BloomFilterBreachChecker
+	}{
+		{"constructed", NewBloomFilterBreachChecker(100, 0.001)},
+		{"zero value", &BloomFilterBreachChecker{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if breached, err := tt.checker.IsBreached(context.Background(), "password1"); err != nil || breached {
+				t.Fatalf("IsBreached of an empty checker = %v, %v, want false", breached, err)
+			}
+
+			tt.checker.AddPassword("password1")
+
+			// The SHA-1 hash of "letmein"
+			if err := tt.checker.AddHash("B7A875FC1EA228B9061041B7CEC4BD3C52AB3CE3"); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, password := range []string{"password1", "letmein"} {
+				if breached, err := tt.checker.IsBreached(context.Background(), password); err != nil || !breached {
+					t.Errorf("IsBreached(%q) = %v, %v, want true", password, breached, err)
+				}
+			}
+
+			if breached, _ := tt.checker.IsBreached(context.Background(), "Correct-Horse-42"); breached {
+				t.Error("IsBreached of a password never added = true, want false")
+			}
+		})
+	}
+}
+
+func TestBloomFilterBreachCheckerLoadHashList(t *testing.T) {
+	checker := NewBloomFilterBreachChecker(10, 0.001)
+
+	// The SHA-1 hashes of "password1" and "letmein" in the Have I Been Pwned format
+	list := "E38AD214943DAAD1D64C102FAEC29DE4AFE9DA3D:2413945\n\nB7A875FC1EA228B9061041B7CEC4BD3C52AB3CE3:100\n"
+
+	if err := checker.LoadHashList(strings.NewReader(list)); err != nil {
+		t.Fatal(err)
+	}
+
+	if breached, _ := checker.IsBreached(context.Background(), "letmein"); !breached {
+		t.Error("IsBreached of a listed password = false, want true")
+	}
+
+	if err := checker.LoadHashList(strings.NewReader("E38AD214943DAAD1D64C102FAEC29DE4AFE9DA3D\nnot-a-hash\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("LoadHashList error = %v, want the invalid hash on line 2 reported", err)
+	}
+}
+
+func TestBloomFilterBreachCheckerUsesTheContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewBloomFilterBreachChecker(10, 0.001).IsBreached(ctx, "password1"); !errors.Is(err, context.Canceled) {
+		t.Errorf("IsBreached error = %v, want %v", err, context.Canceled)
+	}
+}
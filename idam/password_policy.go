@@ -0,0 +1,226 @@
+package idam
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/dmars8047/strval"
+)
+
+// PasswordPolicy describes the rules an application's user passwords must satisfy
+type PasswordPolicy struct {
+	// Minimum password length
+	MinLength int `json:"min_length"`
+	// Maximum password length
+	MaxLength int `json:"max_length"`
+	// Require at least one uppercase letter
+	RequireUppercase bool `json:"require_uppercase"`
+	// Require at least one lowercase letter
+	RequireLowercase bool `json:"require_lowercase"`
+	// Require at least one number
+	RequireNumber bool `json:"require_number"`
+	// Require at least one of the AllowedSpecialCharacters
+	RequireSpecialCharacter bool `json:"require_special_character"`
+	// Allowable (require one) special characters for passwords
+	AllowedSpecialCharacters string `json:"allowed_special_characters"`
+	// Disallowed characters for passwords
+	DisallowedCharacters string `json:"disallowed_characters"`
+	// Allow non-ASCII characters
+	AllowUnicode bool `json:"allow_unicode"`
+	// Minimum score from EstimatePasswordStrength, zero disables the strength check
+	MinStrengthScore int `json:"min_strength_score"`
+	// Rejects passwords known to have appeared in a data breach when set
+	BreachChecker BreachedPasswordChecker `json:"-"`
+	// Accept passwords the BreachChecker fails to check instead of failing validation.
+	// By default a checker error is returned by the Context validation methods, and reported as a
+	// breach_check_failed validation error by the methods without a context.
+	BreachCheckFailOpen bool `json:"-"`
+}
+
+// DefaultPasswordPolicy returns the password policy applied by the request Validate methods
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:                MinPasswordLength,
+		MaxLength:                MaxPasswordLength,
+		RequireUppercase:         true,
+		RequireLowercase:         true,
+		RequireNumber:            true,
+		RequireSpecialCharacter:  true,
+		AllowedSpecialCharacters: AllowablePasswordSpecialCharacters,
+		DisallowedCharacters:     DisallowedPassowrdSpecialCharacters,
+		AllowUnicode:             false,
+	}
+}
+
+// Validate validates the password against the policy, returning the validation messages if it is invalid.
+// The userInputs (e.g. the username and email) are treated as easily guessed by the strength check.
+func (policy PasswordPolicy) Validate(password string, userInputs ...string) []string {
+	validationErrors, err := policy.validate(context.Background(), password, "password", userInputs...)
+	return withBreachCheckFailure(validationErrors, "password", err).Messages()
+}
+
+// ValidateContext validates the password against the policy using the provided context,
+// returning the validation messages if it is invalid.
+// An error is returned if the BreachChecker fails, unless the policy fails open.
+func (policy PasswordPolicy) ValidateContext(ctx context.Context, password string, userInputs ...string) ([]string, error) {
+	validationErrors, err := policy.validate(ctx, password, "password", userInputs...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return validationErrors.Messages(), nil
+}
+
+// validate validates the password against the policy using the name to identify the password field.
+// An error is returned if the BreachChecker fails, unless the policy fails open.
+func (policy PasswordPolicy) validate(ctx context.Context, password string, name string, userInputs ...string) (ValidationErrors, error) {
+	rules := []validationRule{rule(ValidationRuleRequired, strval.MustNotBeEmpty())}
+
+	// Lengths are counted in characters rather than bytes when non-ASCII characters are allowed
+	if policy.MinLength > 0 {
+		if policy.AllowUnicode {
+			rules = append(rules, rule(ValidationRuleMinLength, mustHaveMinCharacterCountOf(policy.MinLength)).with("length", strconv.Itoa(policy.MinLength)))
+		} else {
+			rules = append(rules, rule(ValidationRuleMinLength, strval.MustHaveMinLengthOf(policy.MinLength)).with("length", strconv.Itoa(policy.MinLength)))
+		}
+	}
+
+	if policy.MaxLength > 0 {
+		if policy.AllowUnicode {
+			rules = append(rules, rule(ValidationRuleMaxLength, mustHaveMaxCharacterCountOf(policy.MaxLength)).with("length", strconv.Itoa(policy.MaxLength)))
+		} else {
+			rules = append(rules, rule(ValidationRuleMaxLength, strval.MustHaveMaxLengthOf(policy.MaxLength)).with("length", strconv.Itoa(policy.MaxLength)))
+		}
+	}
+
+	if policy.RequireSpecialCharacter && policy.AllowedSpecialCharacters != "" {
+		rules = append(rules, rule(ValidationRuleSpecialCharacter, strval.MustContainAtLeastOne([]rune(policy.AllowedSpecialCharacters))).with("characters", policy.AllowedSpecialCharacters))
+	}
+
+	if policy.DisallowedCharacters != "" {
+		rules = append(rules, rule(ValidationRuleDisallowedCharacters, strval.MustNotContainAnyOf([]rune(policy.DisallowedCharacters))).with("characters", policy.DisallowedCharacters))
+	}
+
+	if policy.RequireNumber {
+		rules = append(rules, rule(ValidationRuleNumber, strval.MustContainNumbers()))
+	}
+
+	// Letter case is checked across all scripts when non-ASCII characters are allowed
+	if policy.RequireUppercase {
+		if policy.AllowUnicode {
+			rules = append(rules, rule(ValidationRuleUppercase, mustContainCharacterMatching(unicode.IsUpper, "uppercase letter")))
+		} else {
+			rules = append(rules, rule(ValidationRuleUppercase, strval.MustContainUppercaseLetter()))
+		}
+	}
+
+	if policy.RequireLowercase {
+		if policy.AllowUnicode {
+			rules = append(rules, rule(ValidationRuleLowercase, mustContainCharacterMatching(unicode.IsLower, "lowercase letter")))
+		} else {
+			rules = append(rules, rule(ValidationRuleLowercase, strval.MustContainLowercaseLetter()))
+		}
+	}
+
+	rules = append(rules, rule(ValidationRulePrintable, strval.MustOnlyContainPrintableCharacters()))
+
+	if !policy.AllowUnicode {
+		rules = append(rules, rule(ValidationRuleASCII, strval.MustOnlyContainASCIICharacters()))
+	}
+
+	if policy.MinStrengthScore > 0 {
+		rules = append(rules, rule(ValidationRuleStrength, mustHaveMinStrengthScoreOf(policy.MinStrengthScore, userInputs)))
+	}
+
+	validationErrors := validateField(password, name, rules...)
+
+	if policy.BreachChecker == nil || password == "" {
+		return validationErrors, nil
+	}
+
+	breached, err := policy.BreachChecker.IsBreached(ctx, password)
+
+	if err != nil {
+		if policy.BreachCheckFailOpen {
+			return validationErrors, nil
+		}
+
+		return validationErrors, fmt.Errorf("error checking %s for data breaches - %w", name, err)
+	}
+
+	if breached {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   name,
+			Rule:    ValidationRuleBreached,
+			Message: fmt.Sprintf("%s has appeared in a data breach and must not be used", name),
+		})
+	}
+
+	return validationErrors, nil
+}
+
+// withBreachCheckFailure reports the error of a failed breach check as a validation error of the password field,
+// for the validation methods that have no way of returning the error
+func withBreachCheckFailure(validationErrors ValidationErrors, name string, err error) ValidationErrors {
+	if err == nil {
+		return validationErrors
+	}
+
+	return append(validationErrors, ValidationError{
+		Field:   name,
+		Rule:    ValidationRuleBreachCheckFailed,
+		Message: fmt.Sprintf("%s could not be checked for data breaches", name),
+	})
+}
+
+// mustHaveMinCharacterCountOf validates that the string has at least minLength characters
+func mustHaveMinCharacterCountOf(minLength int) strval.StringValidationOption {
+	return func(str, strName string) error {
+		if utf8.RuneCountInString(str) < minLength {
+			return fmt.Errorf("%s must have a minimum length of %d", strName, minLength)
+		}
+
+		return nil
+	}
+}
+
+// mustHaveMaxCharacterCountOf validates that the string has at most maxLength characters
+func mustHaveMaxCharacterCountOf(maxLength int) strval.StringValidationOption {
+	return func(str, strName string) error {
+		if utf8.RuneCountInString(str) > maxLength {
+			return fmt.Errorf("%s must have a maximum length of %d", strName, maxLength)
+		}
+
+		return nil
+	}
+}
+
+// mustContainCharacterMatching validates that the string contains at least one character matching the predicate
+func mustContainCharacterMatching(predicate func(rune) bool, description string) strval.StringValidationOption {
+	return func(str, strName string) error {
+		if strings.IndexFunc(str, predicate) < 0 {
+			return fmt.Errorf("%s must contain at least one %s", strName, description)
+		}
+
+		return nil
+	}
+}
+
+// mustHaveMinStrengthScoreOf validates that the estimated strength of the string is at least minScore.
+// The estimator's suggestions are included in the message.
+func mustHaveMinStrengthScoreOf(minScore int, userInputs []string) strval.StringValidationOption {
+	return func(str, strName string) error {
+		strength := EstimatePasswordStrength(str, userInputs...)
+
+		if strength.Score < minScore {
+			return fmt.Errorf("%s is too easy to guess: %s", strName, strings.Join(strength.Feedback, "; "))
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,160 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// breachCheckerFunc adapts a function to a BreachedPasswordChecker
+type breachCheckerFunc func(ctx context.Context, password string) (bool, error)
+
+func (f breachCheckerFunc) IsBreached(ctx context.Context, password string) (bool, error) {
+	return f(ctx, password)
+}
+
+type testContextKey struct{}
+
+// ruleNames returns the rule of each validation error
+func ruleNames(validationErrors ValidationErrors) []string {
+	names := []string{}
+
+	for _, err := range validationErrors {
+		names = append(names, err.Rule)
+	}
+
+	return names
+}
+
+func TestPasswordPolicyRules(t *testing.T) {
+	unicodePolicy := DefaultPasswordPolicy()
+	unicodePolicy.AllowUnicode = true
+	unicodePolicy.MinLength = 10
+
+	relaxedPolicy := PasswordPolicy{MinLength: 4}
+
+	tests := []struct {
+		name     string
+		policy   PasswordPolicy
+		password string
+		want     []string
+	}{
+		{"valid", DefaultPasswordPolicy(), "Valid1!Password", []string{}},
+		{"empty", DefaultPasswordPolicy(), "", []string{ValidationRuleRequired, ValidationRuleMinLength, ValidationRuleSpecialCharacter, ValidationRuleNumber, ValidationRuleUppercase, ValidationRuleLowercase}},
+		{"too short", DefaultPasswordPolicy(), "Va1!d", []string{ValidationRuleMinLength}},
+		{"too long", DefaultPasswordPolicy(), "Valid1!" + strings.Repeat("a", MaxPasswordLength), []string{ValidationRuleMaxLength}},
+		{"no uppercase", DefaultPasswordPolicy(), "valid1!password", []string{ValidationRuleUppercase}},
+		{"no lowercase", DefaultPasswordPolicy(), "VALID1!PASSWORD", []string{ValidationRuleLowercase}},
+		{"no number", DefaultPasswordPolicy(), "Valid!Password", []string{ValidationRuleNumber}},
+		{"no special character", DefaultPasswordPolicy(), "Valid1Password", []string{ValidationRuleSpecialCharacter}},
+		{"disallowed character", DefaultPasswordPolicy(), "Valid1!Pass<word", []string{ValidationRuleDisallowedCharacters}},
+		{"non-ascii", DefaultPasswordPolicy(), "Välid1!Password", []string{ValidationRuleASCII}},
+		{"non-ascii allowed", unicodePolicy, "Välid1!Pässword", []string{}},
+		{"unicode length counted in characters", unicodePolicy, "Ää1!ääääää", []string{}},
+		{"unicode too short", unicodePolicy, "Ää1!äääää", []string{ValidationRuleMinLength}},
+		{"relaxed policy", relaxedPolicy, "password", []string{}},
+		{"relaxed policy too short", relaxedPolicy, "pwd", []string{ValidationRuleMinLength}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validationErrors, err := tt.policy.validate(context.Background(), tt.password, "password")
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := ruleNames(validationErrors); !slices.Equal(got, tt.want) {
+				t.Errorf("rules = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestsValidateWithThePolicy(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.MinLength = 20
+
+	tests := []struct {
+		name  string
+		field string
+		check func(policy PasswordPolicy) ValidationErrors
+	}{
+		{"registration", "password", func(policy PasswordPolicy) ValidationErrors {
+			request := UserRegistrationRequest{Username: "alice", Email: "alice@example.com", Password: "Valid1!Password"}
+			return request.ValidateFieldsWith(policy)
+		}},
+		{"password reset", "new_password", func(policy PasswordPolicy) ValidationErrors {
+			request := UserPasswordResetExecutionRequest{UserID: "user-1", PasswordResetToken: "token-1", VerificationCode: "123456", NewPassword: "Valid1!Password"}
+			return request.ValidateFieldsWith(policy)
+		}},
+		{"password change", "new_password", func(policy PasswordPolicy) ValidationErrors {
+			request := UserPasswordChangeRequest{CurrentPassword: "Current1!Password", NewPassword: "Valid1!Password"}
+			return request.ValidateFieldsWith(policy)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if validationErrors := tt.check(DefaultPasswordPolicy()); len(validationErrors) != 0 {
+				t.Fatalf("errors with the default policy = %v, want none", validationErrors)
+			}
+
+			validationErrors := tt.check(policy)
+
+			if got := ruleNames(validationErrors.Field(tt.field)); !slices.Equal(got, []string{ValidationRuleMinLength}) {
+				t.Errorf("rules of %s = %v, want [%s]", tt.field, got, ValidationRuleMinLength)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyBreachCheckUsesCallerContext(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.BreachChecker = breachCheckerFunc(func(ctx context.Context, password string) (bool, error) {
+		return ctx.Value(testContextKey{}) == "caller", nil
+	})
+
+	ctx := context.WithValue(context.Background(), testContextKey{}, "caller")
+	messages, err := policy.ValidateContext(ctx, "Valid1!Password")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 1 {
+		t.Errorf("messages = %q, want the breached message reported with the caller's context", messages)
+	}
+}
+
+func TestPasswordPolicyBreachCheckFailure(t *testing.T) {
+	checkerErr := errors.New("breach service unavailable")
+
+	policy := DefaultPasswordPolicy()
+	policy.BreachChecker = breachCheckerFunc(func(ctx context.Context, password string) (bool, error) {
+		return false, checkerErr
+	})
+
+	if _, err := policy.ValidateContext(context.Background(), "Valid1!Password"); !errors.Is(err, checkerErr) {
+		t.Errorf("ValidateContext error = %v, want the checker error", err)
+	}
+
+	request := UserPasswordResetExecutionRequest{NewPassword: "Valid1!Password"}
+	validationErrors := request.ValidateFieldsWith(policy)
+
+	if !slices.ContainsFunc(validationErrors, func(err ValidationError) bool { return err.Rule == ValidationRuleBreachCheckFailed }) {
+		t.Errorf("ValidateFieldsWith = %v, want a %s error", validationErrors, ValidationRuleBreachCheckFailed)
+	}
+
+	policy.BreachCheckFailOpen = true
+
+	if messages, err := policy.ValidateContext(context.Background(), "Valid1!Password"); err != nil || len(messages) != 0 {
+		t.Errorf("ValidateContext = %q, %v, want the password accepted when failing open", messages, err)
+	}
+
+	if validationErrors := request.ValidateFieldsWith(policy); len(validationErrors) != 0 {
+		t.Errorf("ValidateFieldsWith = %v, want the password accepted when failing open", validationErrors)
+	}
+}
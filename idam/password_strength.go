@@ -0,0 +1,434 @@
+package idam
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Password strength scores, from easily guessed to very hard to guess
+const (
+	PasswordStrengthVeryWeak = iota
+	PasswordStrengthWeak
+	PasswordStrengthFair
+	PasswordStrengthStrong
+	PasswordStrengthVeryStrong
+)
+
+// The minimum estimated entropy in bits for each score above PasswordStrengthVeryWeak
+var passwordStrengthThresholds = [...]float64{20, 30, 40, 50}
+
+// PasswordStrength is the result of estimating how hard a password is to guess
+type PasswordStrength struct {
+	// The score from PasswordStrengthVeryWeak (0) to PasswordStrengthVeryStrong (4)
+	Score int
+	// The estimated number of guesses required, as bits of entropy
+	Entropy float64
+	// Human readable suggestions for making the password stronger
+	Feedback []string
+}
+
+// Feedback messages returned by EstimatePasswordStrength
+const (
+	feedbackCommonPassword  = "avoid common passwords and words"
+	feedbackSubstitutions   = "predictable substitutions like '@' instead of 'a' do not help much"
+	feedbackCapitalization  = "capitalizing the first letter does not help much"
+	feedbackSequence        = "avoid sequences like abc or 123"
+	feedbackRepeat          = "avoid repeated characters like aaa"
+	feedbackKeyboardPattern = "avoid keyboard patterns like qwerty"
+	feedbackYear            = "avoid years and dates that are associated with you"
+	feedbackUserInput       = "avoid using your username or email address in your password"
+	feedbackLength          = "add another word or two, uncommon words are better"
+)
+
+// passwordMatch is a guessable pattern found in a password
+type passwordMatch struct {
+	length   int
+	entropy  float64
+	feedback []string
+}
+
+// EstimatePasswordStrength estimates how hard the password is to guess, entirely offline.
+// Like zxcvbn, the password is split into common words, sequences, repeats, keyboard patterns and years,
+// each of which costs far fewer guesses than random characters. Any userInputs (e.g. the username and email)
+// found in the password are treated as known to an attacker.
+func EstimatePasswordStrength(password string, userInputs ...string) PasswordStrength {
+	runes := []rune(password)
+	lower := []rune(strings.ToLower(password))
+	charsetBits := math.Log2(float64(passwordCharsetSize(runes)))
+
+	var entropy float64
+	var feedback []string
+
+	for i := 0; i < len(runes); {
+		match, ok := bestPasswordMatch(runes, lower, i, userInputs)
+
+		// Characters that are not part of a pattern are assumed to be random
+		if !ok || match.entropy >= charsetBits*float64(match.length) {
+			entropy += charsetBits
+			i++
+			continue
+		}
+
+		entropy += match.entropy
+		feedback = appendUnique(feedback, match.feedback...)
+		i += match.length
+	}
+
+	score := PasswordStrengthVeryWeak
+
+	for _, threshold := range passwordStrengthThresholds {
+		if entropy >= threshold {
+			score++
+		}
+	}
+
+	if score < PasswordStrengthStrong {
+		feedback = appendUnique(feedback, feedbackLength)
+	}
+
+	return PasswordStrength{
+		Score:    score,
+		Entropy:  entropy,
+		Feedback: feedback,
+	}
+}
+
+// passwordCharsetSize estimates the size of the character set the password was drawn from
+func passwordCharsetSize(runes []rune) int {
+	var hasLower, hasUpper, hasDigit, hasSymbol, hasOther bool
+
+	for _, r := range runes {
+		switch {
+		case r >= 'a' && r <= 'z':
+			hasLower = true
+		case r >= 'A' && r <= 'Z':
+			hasUpper = true
+		case r >= '0' && r <= '9':
+			hasDigit = true
+		case r < unicode.MaxASCII:
+			hasSymbol = true
+		default:
+			hasOther = true
+		}
+	}
+
+	size := 0
+
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{hasLower, 26}, {hasUpper, 26}, {hasDigit, 10}, {hasSymbol, 33}, {hasOther, 100}} {
+		if class.present {
+			size += class.size
+		}
+	}
+
+	return max(size, 1)
+}
+
+// bestPasswordMatch finds the longest guessable pattern starting at position start
+func bestPasswordMatch(runes []rune, lower []rune, start int, userInputs []string) (passwordMatch, bool) {
+	var best passwordMatch
+
+	for _, matcher := range []func() (passwordMatch, bool){
+		func() (passwordMatch, bool) { return matchUserInput(lower, start, userInputs) },
+		func() (passwordMatch, bool) { return matchDictionaryWord(runes, lower, start) },
+		func() (passwordMatch, bool) { return matchRepeat(lower, start) },
+		func() (passwordMatch, bool) { return matchSequence(lower, start) },
+		func() (passwordMatch, bool) { return matchKeyboardPattern(lower, start) },
+		func() (passwordMatch, bool) { return matchYear(lower, start) },
+	} {
+		if match, ok := matcher(); ok && match.length > best.length {
+			best = match
+		}
+	}
+
+	return best, best.length > 0
+}
+
+// matchUserInput matches the username, email or other user specific values
+func matchUserInput(lower []rune, start int, userInputs []string) (passwordMatch, bool) {
+	var best passwordMatch
+
+	for _, input := range userInputs {
+		// The local part of an email address is as guessable as the whole address
+		input, _, _ = strings.Cut(strings.ToLower(input), "@")
+
+		if len([]rune(input)) < 3 || !hasPrefixAt(lower, start, []rune(input)) {
+			continue
+		}
+
+		if length := len([]rune(input)); length > best.length {
+			best = passwordMatch{length: length, entropy: 1, feedback: []string{feedbackUserInput}}
+		}
+	}
+
+	return best, best.length > 0
+}
+
+// matchDictionaryWord matches a common password or word, allowing capitalization and common substitutions
+func matchDictionaryWord(runes []rune, lower []rune, start int) (passwordMatch, bool) {
+	var best passwordMatch
+
+	for rank, word := range commonPasswords {
+		wordRunes := []rune(word)
+
+		if len(wordRunes) <= best.length {
+			continue
+		}
+
+		substituted, ok := matchWordAt(lower, start, wordRunes)
+
+		if !ok {
+			continue
+		}
+
+		match := passwordMatch{
+			length:   len(wordRunes),
+			entropy:  math.Log2(float64(rank + 2)),
+			feedback: []string{feedbackCommonPassword},
+		}
+
+		// Each variation roughly doubles the guesses required
+		if substituted {
+			match.entropy++
+			match.feedback = append(match.feedback, feedbackSubstitutions)
+		}
+
+		if hasUpper(runes[start : start+len(wordRunes)]) {
+			match.entropy++
+			match.feedback = append(match.feedback, feedbackCapitalization)
+		}
+
+		best = match
+	}
+
+	return best, best.length > 0
+}
+
+// matchWordAt reports whether the word is at position start of the lowercased password,
+// each character matching either as is or with a leet substitution undone, and whether any substitution was needed.
+// Words containing digits or symbols (e.g. "trustno1") match their literal characters.
+func matchWordAt(lower []rune, start int, word []rune) (substituted bool, ok bool) {
+	if start+len(word) > len(lower) {
+		return false, false
+	}
+
+	for i, r := range word {
+		if lower[start+i] == r {
+			continue
+		}
+
+		if sub, isLeet := leetSubstitutions[lower[start+i]]; !isLeet || sub != r {
+			return false, false
+		}
+
+		substituted = true
+	}
+
+	return substituted, true
+}
+
+// matchRepeat matches a run of the same character
+func matchRepeat(lower []rune, start int) (passwordMatch, bool) {
+	length := 1
+
+	for start+length < len(lower) && lower[start+length] == lower[start] {
+		length++
+	}
+
+	if length < 3 {
+		return passwordMatch{}, false
+	}
+
+	return passwordMatch{
+		length:   length,
+		entropy:  math.Log2(float64(passwordCharsetSize(lower[start:start+1]))) + math.Log2(float64(length)),
+		feedback: []string{feedbackRepeat},
+	}, true
+}
+
+// matchSequence matches a run of consecutive letters or digits, ascending or descending
+func matchSequence(lower []rune, start int) (passwordMatch, bool) {
+	if start+1 >= len(lower) {
+		return passwordMatch{}, false
+	}
+
+	delta := lower[start+1] - lower[start]
+
+	if (delta != 1 && delta != -1) || !isSequenceCharacter(lower[start]) {
+		return passwordMatch{}, false
+	}
+
+	length := 2
+
+	for start+length < len(lower) && lower[start+length]-lower[start+length-1] == delta && isSequenceCharacter(lower[start+length]) {
+		length++
+	}
+
+	if length < 3 {
+		return passwordMatch{}, false
+	}
+
+	return passwordMatch{
+		length:   length,
+		entropy:  math.Log2(26) + math.Log2(float64(length)) + 1,
+		feedback: []string{feedbackSequence},
+	}, true
+}
+
+// matchKeyboardPattern matches a run of adjacent keys along a row or column of a qwerty keyboard
+func matchKeyboardPattern(lower []rune, start int) (passwordMatch, bool) {
+	var best int
+
+	for _, pattern := range keyboardPatterns {
+		for _, candidate := range []string{pattern, reverseString(pattern)} {
+			candidateRunes := []rune(candidate)
+
+			for offset := range candidateRunes {
+				length := 0
+
+				for start+length < len(lower) && offset+length < len(candidateRunes) && lower[start+length] == candidateRunes[offset+length] {
+					length++
+				}
+
+				best = max(best, length)
+			}
+		}
+	}
+
+	if best < 4 {
+		return passwordMatch{}, false
+	}
+
+	return passwordMatch{
+		length:   best,
+		entropy:  math.Log2(47) + math.Log2(float64(best)) + 1,
+		feedback: []string{feedbackKeyboardPattern},
+	}, true
+}
+
+// matchYear matches a recent year such as 1987 or 2024
+func matchYear(lower []rune, start int) (passwordMatch, bool) {
+	if start+4 > len(lower) {
+		return passwordMatch{}, false
+	}
+
+	year := string(lower[start : start+4])
+
+	if !(strings.HasPrefix(year, "19") || strings.HasPrefix(year, "20")) || strings.IndexFunc(year, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+		return passwordMatch{}, false
+	}
+
+	return passwordMatch{
+		length:   4,
+		entropy:  math.Log2(120),
+		feedback: []string{feedbackYear},
+	}, true
+}
+
+// hasPrefixAt reports whether value contains prefix at position start
+func hasPrefixAt(value []rune, start int, prefix []rune) bool {
+	if start+len(prefix) > len(value) {
+		return false
+	}
+
+	for i, r := range prefix {
+		if value[start+i] != r {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasUpper reports whether any of the runes is uppercase
+func hasUpper(runes []rune) bool {
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSequenceCharacter reports whether the rune can be part of an alphabetical or numerical sequence
+func isSequenceCharacter(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9')
+}
+
+// reverseString reverses the characters of the string
+func reverseString(value string) string {
+	runes := []rune(value)
+
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+
+	return string(runes)
+}
+
+// appendUnique appends the values not already present in the slice
+func appendUnique(slice []string, values ...string) []string {
+	for _, value := range values {
+		found := false
+
+		for _, existing := range slice {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			slice = append(slice, value)
+		}
+	}
+
+	return slice
+}
+
+// leetSubstitutions maps common character substitutions back to the letter they replace
+var leetSubstitutions = map[rune]rune{
+	'@': 'a',
+	'4': 'a',
+	'8': 'b',
+	'(': 'c',
+	'3': 'e',
+	'6': 'g',
+	'1': 'i',
+	'!': 'i',
+	'|': 'l',
+	'0': 'o',
+	'$': 's',
+	'5': 's',
+	'7': 't',
+	'+': 't',
+	'2': 'z',
+}
+
+// keyboardPatterns are the rows and columns of a qwerty keyboard
+var keyboardPatterns = []string{
+	"`1234567890-=",
+	"qwertyuiop[]\\",
+	"asdfghjkl;'",
+	"zxcvbnm,./",
+	"1qaz", "2wsx", "3edc", "4rfv", "5tgb", "6yhn", "7ujm", "8ik,", "9ol.", "0p;/",
+}
+
+// commonPasswords are frequently used passwords and words, most common first
+var commonPasswords = []string{
+	"password", "123456", "qwerty", "letmein", "welcome", "admin", "login", "abc123", "monkey", "dragon",
+	"master", "sunshine", "princess", "football", "baseball", "iloveyou", "trustno1", "shadow", "superman", "batman",
+	"michael", "jennifer", "hunter", "charlie", "jordan", "thomas", "daniel", "andrew", "joshua", "jessica",
+	"ashley", "matthew", "robert", "hannah", "summer", "winter", "spring", "autumn", "secret", "freedom",
+	"whatever", "starwars", "pokemon", "computer", "internet", "hello", "love", "angel", "flower", "cookie",
+	"cheese", "coffee", "orange", "banana", "purple", "silver", "golden", "tiger", "killer", "soccer",
+	"hockey", "ranger", "buster", "pepper", "ginger", "maggie", "thunder", "mustang", "harley", "yankees",
+	"liverpool", "chelsea", "arsenal", "google", "apple", "samsung", "access", "passw", "pass", "change",
+	"changeme", "default", "guest", "test", "user", "root", "temp", "qazwsx", "zaq1", "asdf",
+	"january", "february", "march", "april", "june", "july", "august", "september", "october", "november",
+	"december", "monday", "friday", "family", "forever", "money", "happy", "lucky", "magic", "music",
+}
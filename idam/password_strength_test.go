@@ -0,0 +1,66 @@
+package idam
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEstimatePasswordStrengthDictionaryWords(t *testing.T) {
+	tests := []struct {
+		password     string
+		maxScore     int
+		wantFeedback []string
+	}{
+		{"123456", PasswordStrengthVeryWeak, []string{feedbackCommonPassword}},
+		{"abc123", PasswordStrengthVeryWeak, []string{feedbackCommonPassword}},
+		{"trustno1", PasswordStrengthVeryWeak, []string{feedbackCommonPassword}},
+		{"Trustno1", PasswordStrengthVeryWeak, []string{feedbackCommonPassword, feedbackCapitalization}},
+		{"trustn01", PasswordStrengthVeryWeak, []string{feedbackCommonPassword, feedbackSubstitutions}},
+		{"password", PasswordStrengthVeryWeak, []string{feedbackCommonPassword}},
+		{"p@ssw0rd", PasswordStrengthVeryWeak, []string{feedbackCommonPassword, feedbackSubstitutions}},
+		{"P@ssw0rd", PasswordStrengthVeryWeak, []string{feedbackCommonPassword, feedbackSubstitutions, feedbackCapitalization}},
+		{"$unshine", PasswordStrengthVeryWeak, []string{feedbackCommonPassword, feedbackSubstitutions}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			strength := EstimatePasswordStrength(tt.password)
+
+			if strength.Score > tt.maxScore {
+				t.Errorf("score = %d, want at most %d", strength.Score, tt.maxScore)
+			}
+
+			for _, feedback := range tt.wantFeedback {
+				if !slices.Contains(strength.Feedback, feedback) {
+					t.Errorf("feedback = %q, want it to contain %q", strength.Feedback, feedback)
+				}
+			}
+		})
+	}
+}
+
+func TestEstimatePasswordStrengthUncommonPasswords(t *testing.T) {
+	tests := []struct {
+		password string
+		minScore int
+	}{
+		{"correct horse battery staple", PasswordStrengthVeryStrong},
+		{"xK9#mQ2$vL7!", PasswordStrengthStrong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			if strength := EstimatePasswordStrength(tt.password); strength.Score < tt.minScore {
+				t.Errorf("score = %d (%.1f bits, feedback %q), want at least %d", strength.Score, strength.Entropy, strength.Feedback, tt.minScore)
+			}
+		})
+	}
+}
+
+func TestEstimatePasswordStrengthUserInputs(t *testing.T) {
+	strength := EstimatePasswordStrength("alicesmith", "alicesmith", "alice@example.com")
+
+	if strength.Score > PasswordStrengthVeryWeak || !slices.Contains(strength.Feedback, feedbackUserInput) {
+		t.Errorf("strength = %+v, want a very weak score with the user input feedback", strength)
+	}
+}
@@ -0,0 +1,73 @@
+package idam
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+)
+
+// PKCEMethodS256 is the only PKCE code challenge method supported, the verifier is hashed with SHA-256
+const PKCEMethodS256 = "S256"
+
+// PKCE is an RFC 7636 proof key binding an authorization code to the client that requested it.
+// The challenge is sent with the authorization request and the verifier with the code exchange.
+type PKCE struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// GeneratePKCE returns a new random code verifier and its S256 challenge
+func GeneratePKCE() (*PKCE, error) {
+	verifier, err := randomURLSafeString(32)
+
+	if err != nil {
+		return nil, fmt.Errorf("error generating pkce code verifier - %v", err)
+	}
+
+	return &PKCE{
+		Verifier:  verifier,
+		Challenge: PKCEChallenge(verifier),
+		Method:    PKCEMethodS256,
+	}, nil
+}
+
+// PKCEChallenge returns the S256 code challenge of the verifier
+func PKCEChallenge(verifier string) string {
+	digest := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// VerifyPKCE reports whether the verifier matches the challenge sent with the authorization request
+func VerifyPKCE(verifier string, challenge string, method string) bool {
+	if method != PKCEMethodS256 || verifier == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(PKCEChallenge(verifier)), []byte(challenge)) == 1
+}
+
+// GenerateState returns a random value for the state parameter of an authorization request.
+// The state is compared on the callback to prevent cross-site request forgery.
+func GenerateState() (string, error) {
+	state, err := randomURLSafeString(16)
+
+	if err != nil {
+		return "", fmt.Errorf("error generating state - %v", err)
+	}
+
+	return state, nil
+}
+
+// randomURLSafeString returns n random bytes encoded as unpadded base64url
+func randomURLSafeString(n int) (string, error) {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
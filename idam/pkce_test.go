@@ -0,0 +1,55 @@
+package idam
+
+import "testing"
+
+// The code verifier and challenge of RFC 7636 Appendix B
+const (
+	rfc7636Verifier  = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	rfc7636Challenge = "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+)
+
+func TestPKCEChallengeRFC7636(t *testing.T) {
+	if got := PKCEChallenge(rfc7636Verifier); got != rfc7636Challenge {
+		t.Errorf("PKCEChallenge = %q, want %q", got, rfc7636Challenge)
+	}
+}
+
+func TestVerifyPKCE(t *testing.T) {
+	tests := []struct {
+		name      string
+		verifier  string
+		challenge string
+		method    string
+		want      bool
+	}{
+		{"RFC 7636 vector", rfc7636Verifier, rfc7636Challenge, PKCEMethodS256, true},
+		{"wrong verifier", rfc7636Verifier[1:], rfc7636Challenge, PKCEMethodS256, false},
+		{"empty verifier", "", rfc7636Challenge, PKCEMethodS256, false},
+		{"plain method", rfc7636Verifier, rfc7636Verifier, "plain", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyPKCE(tt.verifier, tt.challenge, tt.method); got != tt.want {
+				t.Errorf("VerifyPKCE = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratePKCE(t *testing.T) {
+	pkce, err := GeneratePKCE()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// RFC 7636 requires a verifier of 43 to 128 characters
+	if len(pkce.Verifier) < 43 || len(pkce.Verifier) > 128 {
+		t.Errorf("verifier length = %d, want 43 to 128", len(pkce.Verifier))
+	}
+
+	if pkce.Method != PKCEMethodS256 || !VerifyPKCE(pkce.Verifier, pkce.Challenge, pkce.Method) {
+		t.Errorf("pkce = %+v, want an S256 challenge of the verifier", pkce)
+	}
+}
@@ -0,0 +1,59 @@
+package idam
+
+import "github.com/dmars8047/strval"
+
+// UserUsernameUpdateRequest is the request object for the current user username update endpoint
+type UserUsernameUpdateRequest struct {
+	Username string `json:"username"`
+}
+
+// Validate validates the username update request using the same rules as registration
+func (request *UserUsernameUpdateRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the username update request, returning the errors of each field
+func (request *UserUsernameUpdateRequest) ValidateFields() ValidationErrors {
+	return validateUsername(request.Username)
+}
+
+// UserEmailChangeRequest is the request object for the current user email change endpoint
+type UserEmailChangeRequest struct {
+	// The new email address, which must be verified before it replaces the current one
+	Email string `json:"email"`
+}
+
+// Validate validates the email change request using the same rules as registration
+func (request *UserEmailChangeRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the email change request, returning the errors of each field
+func (request *UserEmailChangeRequest) ValidateFields() ValidationErrors {
+	return validateEmail(request.Email)
+}
+
+// UserEmailChangeConfirmationRequest is the request object for the email change confirmation endpoint
+type UserEmailChangeConfirmationRequest struct {
+	UserId string `json:"user_id"`
+	// The verification token sent to the new email address
+	VerificationToken string `json:"verification_token"`
+}
+
+// Validate validates the email change confirmation request
+func (request *UserEmailChangeConfirmationRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the email change confirmation request, returning the errors of each field
+func (request *UserEmailChangeConfirmationRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateField(request.UserId, "user_id",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	validationErrors = append(validationErrors, validateField(request.VerificationToken, "verification_token",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	return validationErrors
+}
@@ -0,0 +1,93 @@
+package idam_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmars8047/idamlib/idam"
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+func TestUpdateUsername(t *testing.T) {
+	server := newFakeServer(t)
+	server.CreateUser("bob", "bob@example.com", testPassword, true)
+	login := loginUser(t, server, "alice", "alice@example.com")
+	client := server.UserAuthClient()
+
+	user, err := client.UpdateUsername(login.Token, &idam.UserUsernameUpdateRequest{Username: "alice2"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Username != "alice2" {
+		t.Errorf("username = %q, want %q", user.Username, "alice2")
+	}
+
+	if _, err := client.UpdateUsername(login.Token, &idam.UserUsernameUpdateRequest{Username: "bob"}); !errors.Is(err, idam.ErrDataConflict) {
+		t.Errorf("UpdateUsername to a username in use error = %v, want %v", err, idam.ErrDataConflict)
+	}
+
+	if _, err := client.UpdateUsername(login.Token, &idam.UserUsernameUpdateRequest{Username: "a"}); !errors.Is(err, idam.ErrRequestValidationFailure) {
+		t.Errorf("UpdateUsername to an invalid username error = %v, want %v", err, idam.ErrRequestValidationFailure)
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	server := newFakeServer(t)
+	login := loginUser(t, server, "alice", "alice@example.com")
+	client := server.UserAuthClient()
+
+	if err := client.RequestEmailChange(idamtest.DefaultApplicationId, login.Token, &idam.UserEmailChangeRequest{Email: "alice@example.org"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The email is only changed once the new address is verified
+	user, err := client.GetCurrentUser(login.Token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Email != "alice@example.com" {
+		t.Errorf("email before confirmation = %q, want the current email", user.Email)
+	}
+
+	verificationToken, ok := server.EmailChangeToken("alice@example.com")
+
+	if !ok {
+		t.Fatal("no email change token was issued")
+	}
+
+	confirmation := &idam.UserEmailChangeConfirmationRequest{UserId: user.Id, VerificationToken: "wrong-token"}
+
+	if err := client.ConfirmEmailChange(idamtest.DefaultApplicationId, confirmation); !errors.Is(err, idam.ErrInvalidUserVerficationToken) {
+		t.Errorf("ConfirmEmailChange with the wrong token error = %v, want %v", err, idam.ErrInvalidUserVerficationToken)
+	}
+
+	confirmation.VerificationToken = verificationToken
+
+	if err := client.ConfirmEmailChange(idamtest.DefaultApplicationId, confirmation); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err = client.GetCurrentUser(login.Token)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Email != "alice@example.org" {
+		t.Errorf("email after confirmation = %q, want the new email", user.Email)
+	}
+}
+
+func TestGetCurrentUserWithAnExpiredToken(t *testing.T) {
+	server := newFakeServer(t)
+	server.SetTokenLifetime(-1)
+	login := loginUser(t, server, "alice", "alice@example.com")
+
+	if _, err := server.UserAuthClient().GetCurrentUser(login.Token); !errors.Is(err, idam.ErrAuthTokenExpired) {
+		t.Errorf("error = %v, want %v", err, idam.ErrAuthTokenExpired)
+	}
+}
@@ -1,6 +1,10 @@
 package idam
 
-import "github.com/dmars8047/strval"
+import (
+	"context"
+
+	"github.com/dmars8047/strval"
+)
 
 // UserPasswordResetExecutionRequest is the request object for the password reset execution endpoint
 type UserPasswordResetExecutionRequest struct {
@@ -14,30 +18,32 @@ type UserPasswordResetExecutionRequest struct {
 	VerificationCode string `json:"verification_code"`
 }
 
-// Validate validates the password reset execution request
+// Validate validates the password reset execution request using the DefaultPasswordPolicy
 func (request *UserPasswordResetExecutionRequest) Validate() (valid bool, errors []string) {
-	// The password must contain at least one special character, number, uppercase letter, and lowercase letter
-	// The password must be at least 8 characters long and have a max length of 64 characters
-	// The password must contain at least one of the following special characters: !@#$%^&*()_+-={}[]|\:,.?
-	// The password must contain only printable characters and ascii characters
-	// The password must not contain any of the following special characters: '"<>`~;
-	passwordValidationResult := strval.ValidateStringWithName(request.NewPassword, "new_password",
-		strval.MustNotBeEmpty(),
-		strval.MustHaveMinLengthOf(MinPasswordLength),
-		strval.MustHaveMaxLengthOf(MaxPasswordLength),
-		strval.MustContainAtLeastOne([]rune(AllowablePasswordSpecialCharacters)),
-		strval.MustNotContainAnyOf([]rune(DisallowedPassowrdSpecialCharacters)),
-		strval.MustContainNumbers(),
-		strval.MustContainUppercaseLetter(),
-		strval.MustContainLowercaseLetter(),
-		strval.MustOnlyContainPrintableCharacters(),
-		strval.MustOnlyContainASCIICharacters())
-
-	if !passwordValidationResult.Valid {
-		return false, passwordValidationResult.Messages
-	}
+	return request.ValidateWith(DefaultPasswordPolicy())
+}
+
+// ValidateWith validates the password reset execution request using the password policy
+func (request *UserPasswordResetExecutionRequest) ValidateWith(policy PasswordPolicy) (valid bool, errors []string) {
+	return request.ValidateFieldsWith(policy).result()
+}
+
+// ValidateFields validates the password reset execution request using the DefaultPasswordPolicy, returning the errors of each field
+func (request *UserPasswordResetExecutionRequest) ValidateFields() ValidationErrors {
+	return request.ValidateFieldsWith(DefaultPasswordPolicy())
+}
 
-	return true, nil
+// ValidateFieldsWith validates the password reset execution request using the password policy, returning the errors of each field.
+// A failed breach check is reported as a breach_check_failed validation error of the new password.
+func (request *UserPasswordResetExecutionRequest) ValidateFieldsWith(policy PasswordPolicy) ValidationErrors {
+	validationErrors, err := request.ValidateFieldsWithContext(context.Background(), policy)
+	return withBreachCheckFailure(validationErrors, "new_password", err)
+}
+
+// ValidateFieldsWithContext validates the password reset execution request using the password policy and the provided context,
+// returning the errors of each field. An error is returned if the policy's breach check fails.
+func (request *UserPasswordResetExecutionRequest) ValidateFieldsWithContext(ctx context.Context, policy PasswordPolicy) (ValidationErrors, error) {
+	return policy.validate(ctx, request.NewPassword, "new_password")
 }
 
 type UserPasswordResetInitiationRequest struct {
@@ -46,14 +52,64 @@ type UserPasswordResetInitiationRequest struct {
 
 // Validates the content of a PasswordResetRequest
 func (request *UserPasswordResetInitiationRequest) Validate() (valid bool, errors []string) {
-	// The email must be not empty and valid email address
-	emailValidationResult := strval.ValidateStringWithName(request.Email, "email",
-		strval.MustNotBeEmpty(),
-		strval.MustBeValidEmailFormat())
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the password reset initiation request, returning the errors of each field
+func (request *UserPasswordResetInitiationRequest) ValidateFields() ValidationErrors {
+	return validateEmail(request.Email)
+}
+
+// UserPasswordChangeRequest is the request object for the current user password change endpoint
+type UserPasswordChangeRequest struct {
+	// The user's current password
+	CurrentPassword string `json:"current_password"`
+	// The new password for the user
+	NewPassword string `json:"new_password"`
+}
+
+// Validate validates the password change request using the DefaultPasswordPolicy
+// The new password must satisfy the same rules as a password reset and differ from the current password.
+func (request *UserPasswordChangeRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateWith(DefaultPasswordPolicy())
+}
+
+// ValidateWith validates the password change request using the password policy
+func (request *UserPasswordChangeRequest) ValidateWith(policy PasswordPolicy) (valid bool, errors []string) {
+	return request.ValidateFieldsWith(policy).result()
+}
+
+// ValidateFields validates the password change request using the DefaultPasswordPolicy, returning the errors of each field
+func (request *UserPasswordChangeRequest) ValidateFields() ValidationErrors {
+	return request.ValidateFieldsWith(DefaultPasswordPolicy())
+}
+
+// ValidateFieldsWith validates the password change request using the password policy, returning the errors of each field.
+// A failed breach check is reported as a breach_check_failed validation error of the new password.
+func (request *UserPasswordChangeRequest) ValidateFieldsWith(policy PasswordPolicy) ValidationErrors {
+	validationErrors, err := request.ValidateFieldsWithContext(context.Background(), policy)
+	return withBreachCheckFailure(validationErrors, "new_password", err)
+}
+
+// ValidateFieldsWithContext validates the password change request using the password policy and the provided context,
+// returning the errors of each field. An error is returned if the policy's breach check fails.
+func (request *UserPasswordChangeRequest) ValidateFieldsWithContext(ctx context.Context, policy PasswordPolicy) (ValidationErrors, error) {
+	var validationErrors ValidationErrors
+
+	validationErrors = append(validationErrors, validateField(request.CurrentPassword, "current_password",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()))...)
+
+	passwordErrors, err := policy.validate(ctx, request.NewPassword, "new_password", request.CurrentPassword)
+	validationErrors = append(validationErrors, passwordErrors...)
 
-	if !emailValidationResult.Valid {
-		return false, emailValidationResult.Messages
+	if request.NewPassword != "" && request.NewPassword == request.CurrentPassword {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   "new_password",
+			Rule:    ValidationRuleNotSameAs,
+			Message: "new_password must not be the same as current_password",
+			Params:  map[string]string{"other": "current_password"},
+		})
 	}
 
-	return true, nil
+	return validationErrors, err
 }
@@ -0,0 +1,117 @@
+package idam
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Role is a named set of permissions assigned to users by the IDAM service, e.g. "admin"
+type Role string
+
+// Permission is an action a user may perform, named "resource:action" by convention, e.g. "orders:write".
+// A granted permission ending in ":*" covers every action of the resource and "*" covers everything.
+type Permission string
+
+// Grants reports whether holding the permission allows the required permission
+func (permission Permission) Grants(required Permission) bool {
+	if permission == required || permission == "*" {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(string(permission), "*")
+
+	return ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(string(required), prefix)
+}
+
+// HasRole reports whether the user has been assigned the role
+func (user *User) HasRole(role Role) bool {
+	return slices.Contains(user.Roles, role)
+}
+
+// HasPermission reports whether any of the user's permissions grants the required permission
+func (user *User) HasPermission(required Permission) bool {
+	for _, permission := range user.Permissions {
+		if permission.Grants(required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Can checks the user has every one of the permissions.
+// An *ErrorResponse with the AccessDenied code, detailing the missing permissions, is returned if they do not.
+// Usage: if err := user.Can("orders:write"); err != nil { ... }
+func (user *User) Can(permissions ...Permission) error {
+	var missing []string
+
+	for _, permission := range permissions {
+		if !user.HasPermission(permission) {
+			missing = append(missing, "missing permission "+string(permission))
+		}
+	}
+
+	if len(missing) > 0 {
+		return NewDetailedErrorResponse(AccessDenied, AccessDeniedMessage, missing...)
+	}
+
+	return nil
+}
+
+// RequirePermission returns net/http middleware that only calls the next handler if the user has every one of the permissions.
+// It fails closed, with no permissions every request is denied.
+// It must be used inside Authenticate, which stores the user in the request context.
+// An AccessDenied ErrorResponse is written if the user is missing a permission,
+// localized with the Localizer given to Authenticate with WithLocalizer.
+// Usage: mux.Handle("/orders", idam.Authenticate(client)(idam.RequirePermission("orders:write")(ordersHandler)))
+func RequirePermission(permissions ...Permission) func(http.Handler) http.Handler {
+	return authorize(func(user *User) error {
+		// Can grants an empty list of permissions, which would let every authenticated user through
+		if len(permissions) == 0 {
+			return NewErrorResponse(AccessDenied, AccessDeniedMessage)
+		}
+
+		return user.Can(permissions...)
+	})
+}
+
+// RequireRole returns net/http middleware that only calls the next handler if the user has at least one of the roles.
+// It fails closed, with no roles every request is denied.
+// It must be used inside Authenticate, which stores the user in the request context.
+// An AccessDenied ErrorResponse is written if the user has none of the roles,
+// localized with the Localizer given to Authenticate with WithLocalizer.
+// Usage: mux.Handle("/admin", idam.Authenticate(client)(idam.RequireRole("admin")(adminHandler)))
+func RequireRole(roles ...Role) func(http.Handler) http.Handler {
+	return authorize(func(user *User) error {
+		for _, role := range roles {
+			if user.HasRole(role) {
+				return nil
+			}
+		}
+
+		return NewErrorResponse(AccessDenied, AccessDeniedMessage)
+	})
+}
+
+// authorize returns middleware calling the next handler if the check of the request's user succeeds
+func authorize(check func(user *User) error) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+
+			// The request was not authenticated, Authenticate is missing from the handler chain
+			if !ok {
+				writeLocalizedErrorResponse(w, r, http.StatusUnauthorized, NewErrorResponse(InvalidAuthToken, InvalidAuthTokenMessage))
+				return
+			}
+
+			if err := check(user); err != nil {
+				writeAuthError(w, r, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package idam
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPermissionGrants(t *testing.T) {
+	tests := []struct {
+		granted  Permission
+		required Permission
+		want     bool
+	}{
+		{"orders:write", "orders:write", true},
+		{"orders:write", "orders:read", false},
+		{"orders:*", "orders:read", true},
+		{"orders:*", "invoices:read", false},
+		{"orders*", "orders-archive:read", false},
+		{"*", "orders:write", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.granted)+" "+string(tt.required), func(t *testing.T) {
+			if got := tt.granted.Grants(tt.required); got != tt.want {
+				t.Errorf("Grants = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserCan(t *testing.T) {
+	user := &User{Roles: []Role{"support"}, Permissions: []Permission{"orders:*", "invoices:read"}}
+
+	if !user.HasRole("support") || user.HasRole("admin") {
+		t.Errorf("HasRole of roles %v is wrong", user.Roles)
+	}
+
+	if err := user.Can("orders:write", "invoices:read"); err != nil {
+		t.Errorf("Can of granted permissions returned error %v", err)
+	}
+
+	err := user.Can("orders:write", "invoices:write")
+
+	if !errors.Is(err, ErrAccessDenied) {
+		t.Fatalf("Can error = %v, want %v", err, ErrAccessDenied)
+	}
+
+	if errorResponse, _ := AsErrorResponse(err); len(errorResponse.Details) != 1 || errorResponse.Details[0] != "missing permission invoices:write" {
+		t.Errorf("details = %v, want the missing permission", errorResponse.Details)
+	}
+}
+
+func TestAuthorizationMiddleware(t *testing.T) {
+	user := &User{Roles: []Role{"support"}, Permissions: []Permission{"orders:read"}}
+
+	tests := []struct {
+		name       string
+		middleware func(http.Handler) http.Handler
+		user       *User
+		wantStatus int
+	}{
+		{"granted permission", RequirePermission("orders:read"), user, http.StatusOK},
+		{"missing permission", RequirePermission("orders:read", "orders:write"), user, http.StatusForbidden},
+		{"no permissions", RequirePermission(), user, http.StatusForbidden},
+		{"assigned role", RequireRole("admin", "support"), user, http.StatusOK},
+		{"missing role", RequireRole("admin"), user, http.StatusForbidden},
+		{"no roles", RequireRole(), user, http.StatusForbidden},
+		{"not authenticated", RequirePermission("orders:read"), nil, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/orders", nil)
+
+			if tt.user != nil {
+				r = r.WithContext(ContextWithUser(r.Context(), tt.user))
+			}
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			if tt.wantStatus == http.StatusForbidden {
+				var errorResponse ErrorResponse
+
+				if err := json.NewDecoder(w.Body).Decode(&errorResponse); err != nil {
+					t.Fatal(err)
+				}
+
+				if errorResponse.Code != AccessDenied {
+					t.Errorf("error code = %v, want %v", errorResponse.Code, AccessDenied)
+				}
+			}
+		})
+	}
+}
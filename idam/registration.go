@@ -1,6 +1,7 @@
 package idam
 
 import (
+	"context"
 	"time"
 
 	"github.com/dmars8047/strval"
@@ -24,55 +25,59 @@ type UserRegistrationResponse struct {
 
 // Registration returns a User object if the registration was successful
 
+// Validate validates the registration request using the DefaultPasswordPolicy
 func (request *UserRegistrationRequest) Validate() (valid bool, errors []string) {
-	validationErrors := make([]string, 0)
+	return request.ValidateWith(DefaultPasswordPolicy())
+}
 
-	// Validate the username
-	// The username must be alphanumeric, be at least 3 characters long, and have a max length of 20 characters
-	usrnameValidationResult := strval.ValidateStringWithName(request.Username, "username",
-		strval.MustNotBeEmpty(),
-		strval.MustBeAlphaNumeric(),
-		strval.MustHaveMinLengthOf(3),
-		strval.MustHaveMaxLengthOf(20))
+// ValidateWith validates the registration request using the password policy
+func (request *UserRegistrationRequest) ValidateWith(policy PasswordPolicy) (valid bool, errors []string) {
+	return request.ValidateFieldsWith(policy).result()
+}
 
-	if !usrnameValidationResult.Valid {
-		validationErrors = append(validationErrors, usrnameValidationResult.Messages...)
-	}
+// ValidateFields validates the registration request using the DefaultPasswordPolicy, returning the errors of each field
+func (request *UserRegistrationRequest) ValidateFields() ValidationErrors {
+	return request.ValidateFieldsWith(DefaultPasswordPolicy())
+}
+
+// ValidateFieldsWith validates the registration request using the password policy, returning the errors of each field.
+// A failed breach check is reported as a breach_check_failed validation error of the password.
+func (request *UserRegistrationRequest) ValidateFieldsWith(policy PasswordPolicy) ValidationErrors {
+	validationErrors, err := request.ValidateFieldsWithContext(context.Background(), policy)
+	return withBreachCheckFailure(validationErrors, "password", err)
+}
+
+// ValidateFieldsWithContext validates the registration request using the password policy and the provided context,
+// returning the errors of each field. An error is returned if the policy's breach check fails.
+func (request *UserRegistrationRequest) ValidateFieldsWithContext(ctx context.Context, policy PasswordPolicy) (ValidationErrors, error) {
+	var validationErrors ValidationErrors
+
+	// Validate the username
+	validationErrors = append(validationErrors, validateUsername(request.Username)...)
 
 	// Validate email
+	validationErrors = append(validationErrors, validateEmail(request.Email)...)
+
+	// Validate the password
+	passwordErrors, err := policy.validate(ctx, request.Password, "password", request.Username, request.Email)
+
+	return append(validationErrors, passwordErrors...), err
+}
+
+// validateUsername validates a username, returning the validation errors if it is invalid
+func validateUsername(username string) ValidationErrors {
+	// The username must be alphanumeric, be at least 3 characters long, and have a max length of 20 characters
+	return validateField(username, "username",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+		rule(ValidationRuleAlphanumeric, strval.MustBeAlphaNumeric()),
+		rule(ValidationRuleMinLength, strval.MustHaveMinLengthOf(3)).with("length", "3"),
+		rule(ValidationRuleMaxLength, strval.MustHaveMaxLengthOf(20)).with("length", "20"))
+}
+
+// validateEmail validates an email address, returning the validation errors if it is invalid
+func validateEmail(email string) ValidationErrors {
 	// The email must be not empty and valid email address
-	emailValidationResult := strval.ValidateStringWithName(request.Email, "email",
-		strval.MustNotBeEmpty(),
-		strval.MustBeValidEmailFormat())
-
-	if !emailValidationResult.Valid {
-		validationErrors = append(validationErrors, emailValidationResult.Messages...)
-	}
-
-	// The password must contain at least one special character, number, uppercase letter, and lowercase letter
-	// The password must be at least 8 characters long and have a max length of 64 characters
-	// The password must contain at least one of the following special characters: !@#$%^&*()_+-={}[]|\:,.?
-	// The password must contain only printable characters and ascii characters
-	// The password must not contain any of the following special characters: '"<>`~;
-	passwordValidationResult := strval.ValidateStringWithName(request.Password, "password",
-		strval.MustNotBeEmpty(),
-		strval.MustHaveMinLengthOf(MinPasswordLength),
-		strval.MustHaveMaxLengthOf(MaxPasswordLength),
-		strval.MustContainAtLeastOne([]rune(AllowablePasswordSpecialCharacters)),
-		strval.MustNotContainAnyOf([]rune(DisallowedPassowrdSpecialCharacters)),
-		strval.MustContainNumbers(),
-		strval.MustContainUppercaseLetter(),
-		strval.MustContainLowercaseLetter(),
-		strval.MustOnlyContainPrintableCharacters(),
-		strval.MustOnlyContainASCIICharacters())
-
-	if !passwordValidationResult.Valid {
-		validationErrors = append(validationErrors, passwordValidationResult.Messages...)
-	}
-
-	if len(validationErrors) > 0 {
-		return false, validationErrors
-	}
-
-	return true, nil
+	return validateField(email, "email",
+		rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+		rule(ValidationRuleEmail, strval.MustBeValidEmailFormat()))
 }
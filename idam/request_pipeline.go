@@ -0,0 +1,310 @@
+package idam
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dmars8047/strval"
+)
+
+// Doer sends an http request and returns the response.
+// *http.Client satisfies this interface.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// DoerFunc is an adapter to allow the use of ordinary functions as a Doer
+type DoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do calls f(req)
+func (f DoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware wraps a Doer with additional behaviour (headers, tracing, logging, metrics, etc).
+// Middleware is applied in the order it is provided, the first middleware being the outermost.
+// Usage:
+//
+//	logging := func(next idam.Doer) idam.Doer {
+//		return idam.DoerFunc(func(req *http.Request) (*http.Response, error) {
+//			log.Printf("%s %s", req.Method, req.URL)
+//			return next.Do(req)
+//		})
+//	}
+type Middleware func(next Doer) Doer
+
+// chainMiddleware wraps the doer with the provided middleware
+func chainMiddleware(doer Doer, middleware ...Middleware) Doer {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		if middleware[i] != nil {
+			doer = middleware[i](doer)
+		}
+	}
+
+	return doer
+}
+
+// The maximum size of a response body read from the IDAM service
+const maxResponseBodySize = 10 << 20
+
+// apiRequest describes a single call to an IDAM service endpoint
+type apiRequest struct {
+	// The http method to use
+	method string
+	// The url suffix of the endpoint, with all path parameters already substituted
+	urlSuffix string
+	// The authorization token to send, if any
+	authToken string
+	// The value to marshal as the JSON request body, if any
+	body any
+	// The form values to send as a urlencoded request body instead of JSON, if any
+	form url.Values
+	// The status code indicating success
+	expectedStatus int
+	// The value to decode the JSON response body into on success, if any
+	response any
+	// A short description of the call used in error messages (e.g. "login user")
+	operation string
+	// Whether the call only reads, so RetryMiddleware may repeat it after a failure. False for any call changing state.
+	retryable bool
+}
+
+// requestExecutor is the single pipeline all calls to the IDAM service go through
+type requestExecutor struct {
+	base         *url.URL
+	baseErr      error
+	defaultAppId string
+	doer         Doer
+	// Absolute urls of endpoints, keyed by url suffix, overriding the url resolved against the base url
+	endpoints map[string]string
+}
+
+// newRequestExecutor creates a requestExecutor for the base url configured with the options.
+// An invalid base url is reported by every call to execute.
+func newRequestExecutor(base *url.URL, baseErr error, options clientOptions) requestExecutor {
+	httpClient := options.httpClient
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	if options.timeout > 0 {
+		timeoutClient := *httpClient
+		timeoutClient.Timeout = options.timeout
+		httpClient = &timeoutClient
+	}
+
+	headers := options.headers.Clone()
+
+	if options.userAgent != "" {
+		if headers == nil {
+			headers = http.Header{}
+		}
+
+		headers.Set("User-Agent", options.userAgent)
+	}
+
+	// Default headers are set first so they are visible to the caller's middleware
+	var middleware []Middleware
+
+	if len(headers) > 0 {
+		middleware = append(middleware, HeadersMiddleware(headers))
+	}
+
+	middleware = append(middleware, options.middleware...)
+
+	return requestExecutor{
+		base:         base,
+		baseErr:      baseErr,
+		defaultAppId: options.defaultAppId,
+		doer:         chainMiddleware(httpClient, middleware...),
+		endpoints:    options.metadata.endpoints(),
+	}
+}
+
+// appUrlSuffix substitutes the appId, or the default app id if it is empty, into the url suffix
+func (executor *requestExecutor) appUrlSuffix(urlSuffix string, appId string) string {
+	param := executor.appPathParam(appId)
+	return replacePathParam(urlSuffix, param.name, param.value)
+}
+
+// appPathParam returns the :appId path parameter with the appId, or the default app id if it is empty
+func (executor *requestExecutor) appPathParam(appId string) pathParam {
+	if appId == "" {
+		appId = executor.defaultAppId
+	}
+
+	return pathParam{":appId", appId}
+}
+
+// replacePathParam substitutes the escaped value for the named path parameter (e.g. ":userId") in the url suffix
+func replacePathParam(urlSuffix string, param string, value string) string {
+	return strings.Replace(urlSuffix, param, url.PathEscape(value), 1)
+}
+
+// pathParam is a named path parameter (e.g. ":userId") and the value substituted for it
+type pathParam struct {
+	name  string
+	value string
+}
+
+// substitutePathParams substitutes the escaped values for the path parameters in the url suffix.
+// ValidationErrors are returned if any value is empty or a dot segment, as the url would address a different endpoint,
+// e.g. the user collection rather than a single user.
+func substitutePathParams(urlSuffix string, params ...pathParam) (string, error) {
+	var validationErrors ValidationErrors
+
+	for _, param := range params {
+		name := strings.TrimPrefix(param.name, ":")
+		validationErrors = append(validationErrors, validateField(param.value, name,
+			rule(ValidationRuleRequired, strval.MustNotBeEmpty()),
+			rule(ValidationRuleNotOneOf, mustNotBeOneOf(".", "..")).with("values", "., .."))...)
+	}
+
+	if len(validationErrors) > 0 {
+		return "", validationErrors
+	}
+
+	for _, param := range params {
+		urlSuffix = replacePathParam(urlSuffix, param.name, param.value)
+	}
+
+	return urlSuffix, nil
+}
+
+// execute sends the request to the IDAM service and decodes the response.
+// A non-success status code is returned as an *ErrorResponse,
+// or a *TransportError if the response body is not an ErrorResponse.
+func (executor *requestExecutor) execute(ctx context.Context, request apiRequest) error {
+	if executor.baseErr != nil {
+		return executor.baseErr
+	}
+
+	resolvedURL, err := executor.resolveUrl(request.urlSuffix)
+
+	if err != nil {
+		return err
+	}
+
+	var body io.Reader
+	contentType := "application/json"
+
+	if request.form != nil {
+		body = strings.NewReader(request.form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	} else if request.body != nil {
+		requestBodyBytes, err := json.Marshal(request.body)
+
+		if err != nil {
+			return err
+		}
+
+		body = bytes.NewReader(requestBodyBytes)
+	}
+
+	if request.retryable {
+		ctx = retryableContext(ctx)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.method, resolvedURL, body)
+
+	if err != nil {
+		return err
+	}
+
+	// Set the content type header
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// Set the Authorization header to the token
+	if request.authToken != "" {
+		req.Header.Set("Authorization", bearerToken(request.authToken))
+	}
+
+	response, err := executor.doer.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer response.Body.Close()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxResponseBodySize))
+
+	if err != nil {
+		return newTransportError(req, response, responseBody, fmt.Errorf("error reading response body from idam service - %v", err))
+	}
+
+	if response.StatusCode != request.expectedStatus {
+		// UnMarhsal the response body into an ErrorResponse object
+		var errorResponse ErrorResponse
+
+		err = json.Unmarshal(responseBody, &errorResponse)
+
+		if err != nil {
+			return newTransportError(req, response, responseBody, fmt.Errorf("error decoding response body from idam service - %v", err))
+		}
+
+		// A JSON body without an error code did not come from the IDAM service, unless it is an OAuth2 error
+		if errorResponse.Code == 0 {
+			if oauth2Err := decodeOAuth2Error(response.StatusCode, responseBody); oauth2Err != nil {
+				return oauth2Err
+			}
+
+			return newTransportError(req, response, responseBody, errors.New("response body is not an idam error response"))
+		}
+
+		errorResponse.HTTPStatus = response.StatusCode
+
+		return &errorResponse
+	}
+
+	if request.response == nil {
+		return nil
+	}
+
+	err = json.Unmarshal(responseBody, request.response)
+
+	if err != nil {
+		return newTransportError(req, response, responseBody, fmt.Errorf("error decoding %s response body from idam service - %v", request.operation, err))
+	}
+
+	return nil
+}
+
+// resolveUrl resolves the url suffix against the base url of the IDAM service,
+// unless the endpoint's url was discovered from the provider metadata
+func (executor *requestExecutor) resolveUrl(urlSuffix string) (string, error) {
+	if executor.baseErr != nil {
+		return "", executor.baseErr
+	}
+
+	if endpoint, ok := executor.endpoints[urlSuffix]; ok {
+		return endpoint, nil
+	}
+
+	suffix, err := url.Parse(urlSuffix)
+
+	if err != nil {
+		return "", err
+	}
+
+	return executor.base.ResolveReference(suffix).String(), nil
+}
+
+// bearerToken prepends "Bearer " to the authToken if it is not already present in any letter case
+func bearerToken(authToken string) string {
+	if token, ok := cutBearerScheme(authToken); ok {
+		return "Bearer " + token
+	}
+
+	return "Bearer " + authToken
+}
@@ -0,0 +1,94 @@
+package idam
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"testing"
+)
+
+func TestExecuteSendsTheRequest(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{"id":"user-1","username":"bob"}`))
+	client := newTestClient(t, server)
+
+	user, err := client.UpdateUsername("token", &UserUsernameUpdateRequest{Username: "bob"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Username != "bob" {
+		t.Errorf("username = %q, want the username of the response", user.Username)
+	}
+
+	request := server.lastRequest(t)
+
+	if request.method != http.MethodPut || request.path != CurrentUserUsernameUrlSuffix {
+		t.Errorf("request = %s %s, want %s %s", request.method, request.path, http.MethodPut, CurrentUserUsernameUrlSuffix)
+	}
+
+	if got := request.header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q, want %q", got, "Bearer token")
+	}
+
+	if got := request.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want %q", got, "application/json")
+	}
+
+	if want := `{"username":"bob"}`; request.body != want {
+		t.Errorf("body = %s, want %s", request.body, want)
+	}
+}
+
+func TestExecuteSubstitutesTheAppId(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+	client := newTestClient(t, server)
+
+	if _, err := client.Login("my app", &UserLoginRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := "/api/idam/user-account/applications/my app/login"; server.lastRequest(t).path != want {
+		t.Errorf("path = %q, want %q", server.lastRequest(t).path, want)
+	}
+}
+
+func TestExecuteReturnsErrorResponses(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusUnauthorized, `{"error_code":20,"error_message":"invalid credentials","error_details":[]}`))
+	client := newTestClient(t, server)
+
+	_, err := client.Login("app", &UserLoginRequest{})
+
+	if !errors.Is(err, ErrInvalidCredentials) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidCredentials)
+	}
+
+	if errorResponse, _ := AsErrorResponse(err); errorResponse.HTTPStatus != http.StatusUnauthorized {
+		t.Errorf("HTTPStatus = %d, want %d", errorResponse.HTTPStatus, http.StatusUnauthorized)
+	}
+}
+
+func TestMiddlewareIsAppliedInOrder(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+
+	var calls []string
+
+	record := func(name string) Middleware {
+		return func(next Doer) Doer {
+			return DoerFunc(func(req *http.Request) (*http.Response, error) {
+				calls = append(calls, name)
+				return next.Do(req)
+			})
+		}
+	}
+
+	client := NewUserAuthClient(server.Client(), server.URL, record("first"), nil, record("second"))
+
+	if _, err := client.GetCurrentUser("token"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"first", "second"}; !slices.Equal(calls, want) {
+		t.Errorf("middleware calls = %v, want %v", calls, want)
+	}
+}
@@ -0,0 +1,189 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy configures how failed requests to the IDAM service are retried.
+// Only transient failures are retried:
+//   - failures to connect, for any request, as the request was never sent
+//   - connection errors and 429, 500, 502, 503 and 504 responses, for read-only calls (e.g. GetCurrentUser or ListSessions)
+//
+// Calls that change state or use up a token (e.g. ChangePassword or VerifyAccount) are not retried once sent,
+// as IDAM may have applied them before the failure.
+// Errors reported by IDAM for the request itself (e.g. InvalidCredentials or DataConflict) are never retried.
+type RetryPolicy struct {
+	// The maximum number of attempts, including the first
+	MaxAttempts int
+	// The delay before the first retry
+	InitialBackoff time.Duration
+	// The maximum delay between attempts. A Retry-After header asking for a longer delay stops retrying.
+	MaxBackoff time.Duration
+	// The factor the delay is multiplied by after each retry
+	Multiplier float64
+}
+
+// DefaultRetryPolicy returns a RetryPolicy making up to 3 attempts with exponential backoff starting at 100ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// RetryMiddleware returns Middleware retrying transient failures according to the policy.
+// Request bodies are rewound between attempts, requests whose body cannot be rewound are not retried.
+// Usage: NewUserAuthClient(http.DefaultClient, baseUrl, RetryMiddleware(DefaultRetryPolicy()))
+func RetryMiddleware(policy RetryPolicy) Middleware {
+	return func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			backoff := policy.InitialBackoff
+
+			for attempt := 1; ; attempt++ {
+				response, err := next.Do(req)
+
+				if attempt >= policy.MaxAttempts || !shouldRetry(req, response, err) {
+					return response, err
+				}
+
+				delay := jitter(backoff)
+
+				if response != nil {
+					if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+						// Give up rather than wait longer than the policy allows
+						if retryAfter > policy.MaxBackoff {
+							return response, err
+						}
+
+						delay = retryAfter
+					}
+				}
+
+				retryReq, rewindErr := rewindRequest(req)
+
+				if rewindErr != nil {
+					return response, err
+				}
+
+				if response != nil {
+					// Drain the body so the connection can be reused
+					io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseBodySize))
+					response.Body.Close()
+				}
+
+				timer := time.NewTimer(delay)
+
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
+
+				req = retryReq
+				backoff = time.Duration(float64(backoff) * policy.Multiplier)
+
+				if backoff > policy.MaxBackoff {
+					backoff = policy.MaxBackoff
+				}
+			}
+		})
+	}
+}
+
+// shouldRetry reports whether the outcome of the request is a transient failure that is safe to retry
+func shouldRetry(req *http.Request, response *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		// Requests that failed to connect were never sent
+		var opErr *net.OpError
+		var dnsErr *net.DNSError
+
+		if (errors.As(err, &opErr) && opErr.Op == "dial") || errors.As(err, &dnsErr) {
+			return true
+		}
+
+		return isRetryable(req)
+	}
+
+	switch response.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return isRetryable(req)
+	default:
+		return false
+	}
+}
+
+// retryableContext marks requests made with the returned context as read-only calls that are safe to repeat
+func retryableContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryableContextKey, true)
+}
+
+// isRetryable reports whether the request was marked as a read-only call that is safe to repeat
+func isRetryable(req *http.Request) bool {
+	retryable, _ := req.Context().Value(retryableContextKey).(bool)
+	return retryable
+}
+
+// rewindRequest returns a copy of the request with a fresh body for another attempt
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req.Clone(req.Context()), nil
+	}
+
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be rewound")
+	}
+
+	body, err := req.GetBody()
+
+	if err != nil {
+		return nil, err
+	}
+
+	retryReq := req.Clone(req.Context())
+	retryReq.Body = body
+
+	return retryReq, nil
+}
+
+// parseRetryAfter parses a Retry-After header given in either seconds or as an http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+// jitter returns a random delay between half and all of the backoff
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 1 {
+		return backoff
+	}
+
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int64N(int64(backoff-half)))
+}
@@ -0,0 +1,100 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// testRetryPolicy retries quickly so the tests do not wait on backoff
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	Multiplier:     2,
+}
+
+func TestRetryMiddlewareRetriesReadOnlyCalls(t *testing.T) {
+	server := newTestServer(t, failingFirst(1, respondWith(http.StatusOK, `{"id":"user-1","username":"alice"}`)))
+	client := newTestClient(t, server, WithRetryPolicy(testRetryPolicy))
+
+	user, err := client.GetCurrentUser("token")
+
+	if err != nil {
+		t.Fatalf("GetCurrentUser returned error %v, want it to succeed after a retry", err)
+	}
+
+	if user.Id != "user-1" {
+		t.Errorf("user id = %q, want %q", user.Id, "user-1")
+	}
+
+	if got := server.requestCount(); got != 2 {
+		t.Errorf("attempts = %d, want 2", got)
+	}
+}
+
+func TestRetryMiddlewareDoesNotRetryStateChangingCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(client *UserAuthClient) error
+	}{
+		{"ChangePassword", func(client *UserAuthClient) error {
+			return client.ChangePassword("token", "Current1!", "Replacement1!")
+		}},
+		{"VerifyAccount", func(client *UserAuthClient) error {
+			return client.VerifyAccount("app", &UserAccountVerificationRequest{})
+		}},
+		{"RevokeSession", func(client *UserAuthClient) error {
+			return client.RevokeSession("token", "session-1")
+		}},
+		{"Login", func(client *UserAuthClient) error {
+			_, err := client.Login("app", &UserLoginRequest{Email: "alice@example.com", Password: "Current1!"})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, failingFirst(1, respondWith(http.StatusNoContent, "")))
+			client := newTestClient(t, server, WithRetryPolicy(testRetryPolicy))
+
+			if err := tt.call(client); !IsTransportError(err) {
+				t.Errorf("error = %v, want the transport error of the first attempt", err)
+			}
+
+			if got := server.requestCount(); got != 1 {
+				t.Errorf("attempts = %d, want 1", got)
+			}
+		})
+	}
+}
+
+func TestShouldRetryConnectFailures(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+
+	stateChanging, _ := http.NewRequest(http.MethodPut, "http://idam.example.com", nil)
+	readOnly, _ := http.NewRequestWithContext(retryableContext(context.Background()), http.MethodGet, "http://idam.example.com", nil)
+
+	tests := []struct {
+		name string
+		req  *http.Request
+		err  error
+		want bool
+	}{
+		{"dial failure of a state changing call", stateChanging, dialErr, true},
+		{"read failure of a state changing call", stateChanging, readErr, false},
+		{"read failure of a read-only call", readOnly, readErr, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldRetry(tt.req, nil, tt.err); got != tt.want {
+				t.Errorf("shouldRetry = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
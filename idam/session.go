@@ -0,0 +1,18 @@
+package idam
+
+import "time"
+
+// Session is a login of a user on a device, which lasts until it is revoked or the user logs out.
+// Refreshing a token keeps the same session.
+type Session struct {
+	Id            string `json:"id"`
+	ApplicationId string `json:"application"`
+	// The User-Agent header of the request that logged in
+	UserAgent string `json:"user_agent"`
+	// The IP address the user logged in from
+	IPAddress     string    `json:"ip_address"`
+	CreatedAtUTC  time.Time `json:"created_at_utc"`
+	LastSeenAtUTC time.Time `json:"last_seen_at_utc"`
+	// Whether this is the session of the token used to list the sessions
+	Current bool `json:"current"`
+}
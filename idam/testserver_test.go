@@ -0,0 +1,120 @@
+package idam
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// recordedRequest is a request received by a testServer
+type recordedRequest struct {
+	method   string
+	path     string
+	rawQuery string
+	header   http.Header
+	body     string
+}
+
+// testServer is an httptest.Server recording the requests it receives before passing them to its handler
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+// newTestServer starts a testServer passing requests to the handler, it is closed when the test finishes
+func newTestServer(t *testing.T, handler http.Handler) *testServer {
+	t.Helper()
+
+	server := &testServer{}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		server.mu.Lock()
+		server.requests = append(server.requests, recordedRequest{
+			method:   r.Method,
+			path:     r.URL.Path,
+			rawQuery: r.URL.RawQuery,
+			header:   r.Header.Clone(),
+			body:     string(body),
+		})
+		server.mu.Unlock()
+
+		handler.ServeHTTP(w, r)
+	}))
+
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// requestCount returns the number of requests the server has received
+func (server *testServer) requestCount() int {
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	return len(server.requests)
+}
+
+// lastRequest returns the most recent request the server received, failing the test if there is none
+func (server *testServer) lastRequest(t *testing.T) recordedRequest {
+	t.Helper()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if len(server.requests) == 0 {
+		t.Fatal("the server received no requests")
+	}
+
+	return server.requests[len(server.requests)-1]
+}
+
+// respondWith returns a handler answering every request with the status and, if not empty, the JSON body
+func respondWith(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if body != "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}
+}
+
+// failingFirst returns a handler answering the first n requests with a 502 and passing the rest to the handler
+func failingFirst(n int, handler http.Handler) http.HandlerFunc {
+	var mu sync.Mutex
+	var received int
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received++
+		fail := received <= n
+		mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	}
+}
+
+// newTestClient creates a UserAuthClient for the server configured with the options
+func newTestClient(t *testing.T, server *testServer, opts ...ClientOption) *UserAuthClient {
+	t.Helper()
+
+	client, err := New(server.URL, opts...)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return client
+}
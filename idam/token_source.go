@@ -0,0 +1,178 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTokenRefreshLeeway is how long before expiry a TokenSource refreshes its token
+	DefaultTokenRefreshLeeway = 30 * time.Second
+	// Maximum duration of a refresh, which is not cancelled with the context of the caller that started it
+	tokenRefreshTimeout = 30 * time.Second
+)
+
+// TokenSource caches the token of a logged in user and refreshes it shortly before it expires.
+// A TokenSource is safe for concurrent use. Concurrent callers share a single in-flight refresh,
+// which each caller stops waiting on when its own context is done.
+type TokenSource struct {
+	appId  string
+	leeway time.Duration
+	// Exchanges the refresh token, the user account refresh endpoint unless the token came from an OAuth2 flow
+	refreshFunc func(ctx context.Context, appId string, refreshToken string) (*UserLoginResponse, error)
+
+	mu        sync.Mutex
+	current   UserLoginResponse
+	expiresAt time.Time
+	inFlight  *tokenRefreshCall
+}
+
+// tokenRefreshCall is a refresh in progress that other callers can wait on
+type tokenRefreshCall struct {
+	done      chan struct{}
+	response  UserLoginResponse
+	expiresAt time.Time
+	err       error
+}
+
+// NewTokenSource creates a TokenSource seeded with the response of a successful login.
+// An error is returned if the login has no token or refresh token, e.g. because it must be completed with VerifyMFA first.
+func NewTokenSource(client *UserAuthClient, appId string, login *UserLoginResponse) (*TokenSource, error) {
+	if login == nil {
+		return nil, errors.New("login response must not be nil")
+	}
+
+	if login.MFARequired() {
+		return nil, errors.New("login response is an mfa challenge, complete the login with VerifyMFA first")
+	}
+
+	if login.Token == "" {
+		return nil, errors.New("login response has no token")
+	}
+
+	if login.RefreshToken == "" {
+		return nil, errors.New("login response has no refresh token")
+	}
+
+	source := &TokenSource{
+		appId:       appId,
+		leeway:      DefaultTokenRefreshLeeway,
+		refreshFunc: client.RefreshContext,
+	}
+
+	source.store(*login)
+
+	return source, nil
+}
+
+// SetRefreshLeeway sets how long before expiry the token is refreshed
+func (source *TokenSource) SetRefreshLeeway(leeway time.Duration) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+
+	source.leeway = leeway
+}
+
+// Token returns the current token, refreshing it first if it is about to expire.
+// A token whose expiry is unknown is never refreshed.
+func (source *TokenSource) Token(ctx context.Context) (*UserLoginResponse, error) {
+	response, _, err := source.token(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+// token returns the current token and when it expires, refreshing it first if it is about to expire
+func (source *TokenSource) token(ctx context.Context) (UserLoginResponse, time.Time, error) {
+	source.mu.Lock()
+
+	if source.expiresAt.IsZero() || time.Until(source.expiresAt) > source.leeway {
+		current, expiresAt := source.current, source.expiresAt
+		source.mu.Unlock()
+		return current, expiresAt, nil
+	}
+
+	// Join a refresh already in progress or start a new one
+	call := source.inFlight
+
+	if call == nil {
+		call = &tokenRefreshCall{done: make(chan struct{})}
+		source.inFlight = call
+
+		// The refresh outlives a caller that gives up waiting, it is shared by every caller waiting on it
+		go source.run(context.WithoutCancel(ctx), call, source.current.RefreshToken)
+	}
+
+	source.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return UserLoginResponse{}, time.Time{}, ctx.Err()
+	}
+
+	if call.err != nil {
+		return UserLoginResponse{}, time.Time{}, call.err
+	}
+
+	return call.response, call.expiresAt, nil
+}
+
+// run refreshes the token for the call, storing the new token if the refresh succeeds
+func (source *TokenSource) run(ctx context.Context, call *tokenRefreshCall, refreshToken string) {
+	ctx, cancel := context.WithTimeout(ctx, tokenRefreshTimeout)
+	defer cancel()
+
+	call.response, call.err = source.refresh(ctx, refreshToken)
+
+	source.mu.Lock()
+	if call.err == nil {
+		source.store(call.response)
+		call.expiresAt = source.expiresAt
+	}
+	source.inFlight = nil
+	source.mu.Unlock()
+
+	close(call.done)
+}
+
+// refresh exchanges the refresh token for a new token.
+// The refresh token is carried over if IDAM does not issue a new one.
+func (source *TokenSource) refresh(ctx context.Context, refreshToken string) (UserLoginResponse, error) {
+	response, err := source.refreshFunc(ctx, source.appId, refreshToken)
+
+	if err != nil {
+		return UserLoginResponse{}, err
+	}
+
+	if response.RefreshToken == "" {
+		response.RefreshToken = refreshToken
+	}
+
+	return *response, nil
+}
+
+// store caches the token and calculates when it expires, must be called with mu held
+func (source *TokenSource) store(response UserLoginResponse) {
+	source.current = response
+	source.expiresAt = tokenExpiry(response)
+}
+
+// tokenExpiry returns when the token expires, read from the token's exp claim if IDAM did not send its lifetime.
+// The zero time is returned if the expiry is unknown, the token is then used without being refreshed.
+func tokenExpiry(response UserLoginResponse) time.Time {
+	if response.ExpiresIn > 0 {
+		return expiry(response.ExpiresIn)
+	}
+
+	if exp := unverifiedExpiry(response.Token); exp > 0 {
+		return time.Unix(exp, 0)
+	}
+
+	return time.Time{}
+}
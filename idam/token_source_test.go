@@ -0,0 +1,119 @@
+package idam
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestTokenSource creates a TokenSource seeded with the login whose refreshes are sent to a testServer answering with the handler
+func newTestTokenSource(t *testing.T, login UserLoginResponse, handler http.Handler) (*TokenSource, *testServer) {
+	t.Helper()
+
+	server := newTestServer(t, handler)
+
+	source, err := NewTokenSource(newTestClient(t, server), "app", &login)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return source, server
+}
+
+// unsignedJWT returns a token with the exp claim, the signature is not valid
+func unsignedJWT(exp int64) string {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	return encode([]byte(`{"alg":"RS256","typ":"at+jwt"}`)) + "." + encode([]byte(fmt.Sprintf(`{"exp":%d}`, exp))) + ".c2ln"
+}
+
+func TestTokenSourceWithoutExpiresIn(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		wantRefreshes int
+	}{
+		{"opaque token is used as is", "opaque-token", 0},
+		{"jwt expiring later is used as is", unsignedJWT(time.Now().Add(time.Hour).Unix()), 0},
+		{"expired jwt is refreshed", unsignedJWT(time.Now().Add(-time.Minute).Unix()), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			login := UserLoginResponse{Token: tt.token, RefreshToken: "refresh-1"}
+			source, server := newTestTokenSource(t, login, respondWith(http.StatusOK, `{"token":"refreshed","expires_in":3600}`))
+
+			if _, err := source.Token(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := server.requestCount(); got != tt.wantRefreshes {
+				t.Errorf("refreshes = %d, want %d", got, tt.wantRefreshes)
+			}
+		})
+	}
+}
+
+func TestTokenSourceSharedRefreshOutlivesCancelledCaller(t *testing.T) {
+	login := UserLoginResponse{Token: "expired", ExpiresIn: 1, RefreshToken: "refresh-1"}
+	release := make(chan struct{})
+
+	source, server := newTestTokenSource(t, login, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		respondWith(http.StatusOK, `{"token":"refreshed","expires_in":3600}`)(w, r)
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := source.Token(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Token error = %v, want %v", err, context.Canceled)
+	}
+
+	close(release)
+
+	response, err := source.Token(context.Background())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response.Token != "refreshed" {
+		t.Errorf("token = %q, want %q", response.Token, "refreshed")
+	}
+
+	if got := server.requestCount(); got != 1 {
+		t.Errorf("refreshes = %d, want the cancelled caller's refresh to be completed and shared", got)
+	}
+}
+
+func TestNewTokenSourceRejectsLoginWithoutTokens(t *testing.T) {
+	client, err := New("http://idam.example.com")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		login *UserLoginResponse
+	}{
+		{"nil login", nil},
+		{"mfa challenge", &UserLoginResponse{MFAChallenge: &MFAChallenge{}}},
+		{"no token", &UserLoginResponse{RefreshToken: "refresh-1"}},
+		{"no refresh token", &UserLoginResponse{Token: "token-1", ExpiresIn: 3600}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTokenSource(client, "app", tt.login); err == nil {
+				t.Error("NewTokenSource returned no error")
+			}
+		})
+	}
+}
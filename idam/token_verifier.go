@@ -0,0 +1,186 @@
+package idam
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+)
+
+// DefaultClockSkew is the tolerance allowed when checking token expiry and not-before times
+const DefaultClockSkew = 30 * time.Second
+
+// Audience is the "aud" claim of a token, which may be encoded as a single string or an array of strings
+type Audience []string
+
+// UnmarshalJSON decodes an audience encoded as either a string or an array of strings
+func (aud *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*aud = Audience{single}
+		return nil
+	}
+
+	var multiple []string
+
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*aud = multiple
+
+	return nil
+}
+
+// Contains reports whether the audience includes the value
+func (aud Audience) Contains(value string) bool {
+	return slices.Contains(aud, value)
+}
+
+// TokenClaims are the claims carried by an access token issued by the IDAM service
+type TokenClaims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  Audience `json:"aud"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf"`
+	IssuedAt  int64    `json:"iat"`
+	// The id of the token, empty if the issuer does not assign one
+	TokenId string `json:"jti"`
+	// The id of the Session the token was issued for, empty if the issuer does not include it
+	SessionId   string       `json:"sid"`
+	Username    string       `json:"username"`
+	Email       string       `json:"email"`
+	Verified    bool         `json:"verified"`
+	Features    []string     `json:"features"`
+	Roles       []Role       `json:"roles"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// User returns the User described by the claims
+func (claims *TokenClaims) User() *User {
+	return &User{
+		Id:          claims.Subject,
+		Username:    claims.Username,
+		Email:       claims.Email,
+		Verified:    claims.Verified,
+		Type:        StandardUserType,
+		Features:    claims.Features,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}
+}
+
+// TokenVerifier validates access tokens locally as signed JWTs without calling the IDAM service.
+// *TokenVerifier satisfies TokenValidator so it can be used with Authenticate.
+type TokenVerifier struct {
+	keys          KeySet
+	issuer        string
+	applicationId string
+
+	mu        sync.RWMutex
+	clockSkew time.Duration
+}
+
+// NewTokenVerifier creates a TokenVerifier checking signatures against the key set.
+// Tokens must have been issued by the issuer for the application (the ApplicationId of the UserLoginResponse).
+// An error is returned if the key set is nil or the issuer or applicationId is empty.
+// Usage: NewTokenVerifier(NewJWKSKeySet(http.DefaultClient, jwksUrl), "https://idam.example.com", appId)
+func NewTokenVerifier(keys KeySet, issuer string, applicationId string) (*TokenVerifier, error) {
+	if keys == nil {
+		return nil, errors.New("token verifier key set must not be nil")
+	}
+
+	if issuer == "" {
+		return nil, errors.New("token verifier issuer must not be empty")
+	}
+
+	if applicationId == "" {
+		return nil, errors.New("token verifier application id must not be empty")
+	}
+
+	return &TokenVerifier{
+		keys:          keys,
+		issuer:        issuer,
+		applicationId: applicationId,
+		clockSkew:     DefaultClockSkew,
+	}, nil
+}
+
+// SetClockSkew sets the tolerance allowed when checking token expiry and not-before times
+func (verifier *TokenVerifier) SetClockSkew(clockSkew time.Duration) {
+	verifier.mu.Lock()
+	defer verifier.mu.Unlock()
+
+	verifier.clockSkew = clockSkew
+}
+
+// Verify checks the access token's type, signature, expiry, issuer and audience and returns its claims.
+// ID tokens are rejected, they must be checked with VerifyIDToken.
+// An *ErrorResponse with the InvalidAuthToken or AuthTokenExpired code is returned if the token is rejected.
+func (verifier *TokenVerifier) Verify(ctx context.Context, token string) (*TokenClaims, error) {
+	var claims struct {
+		TokenClaims
+		// Only ID tokens carry a nonce
+		Nonce string `json:"nonce"`
+	}
+
+	if err := verifyJWT(ctx, verifier.keys, token, accessTokenJWT, &claims); err != nil {
+		return nil, err
+	}
+
+	if err := verifier.checkClaims(claims.Issuer, claims.Audience, claims.ExpiresAt, claims.NotBefore); err != nil {
+		return nil, err
+	}
+
+	if claims.Nonce != "" {
+		return nil, invalidAuthToken("token is not an access token")
+	}
+
+	return &claims.TokenClaims, nil
+}
+
+// checkClaims checks the expiry, not-before time, issuer and audience claims shared by access and ID tokens
+func (verifier *TokenVerifier) checkClaims(issuer string, audience Audience, expiresAt int64, notBefore int64) error {
+	verifier.mu.RLock()
+	clockSkew := verifier.clockSkew
+	verifier.mu.RUnlock()
+
+	now := time.Now()
+
+	if expiresAt == 0 {
+		return invalidAuthToken("token has no expiry")
+	}
+
+	if now.After(time.Unix(expiresAt, 0).Add(clockSkew)) {
+		return NewErrorResponse(AuthTokenExpired, AuthTokenExpiredMessage)
+	}
+
+	if notBefore != 0 && now.Add(clockSkew).Before(time.Unix(notBefore, 0)) {
+		return invalidAuthToken("token is not valid yet")
+	}
+
+	if issuer != verifier.issuer {
+		return invalidAuthToken("token issuer is invalid")
+	}
+
+	if !audience.Contains(verifier.applicationId) {
+		return invalidAuthToken("token audience is invalid")
+	}
+
+	return nil
+}
+
+// ValidateTokenContext verifies the token and returns the User described by its claims
+func (verifier *TokenVerifier) ValidateTokenContext(ctx context.Context, authToken string) (*User, error) {
+	claims, err := verifier.Verify(ctx, authToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return claims.User(), nil
+}
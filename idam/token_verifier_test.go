@@ -0,0 +1,231 @@
+package idam
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testIssuer = "https://idam.example.com"
+	testAppId  = "app"
+)
+
+// staticKeySet is a KeySet of fixed keys
+type staticKeySet map[string]crypto.PublicKey
+
+func (keySet staticKeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := keySet[kid]
+
+	if !ok {
+		return nil, ErrSigningKeyNotFound
+	}
+
+	return key, nil
+}
+
+// signTestJWT signs the header and claims with the key using RS256, whatever alg the header names
+func signTestJWT(t *testing.T, key *rsa.PrivateKey, header map[string]any, claims map[string]any) string {
+	t.Helper()
+
+	encode := func(v any) string {
+		b, err := json.Marshal(v)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	signingInput := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// withChanges returns a copy of the values with the changes applied, a nil change removes the value
+func withChanges(values map[string]any, changes map[string]any) map[string]any {
+	merged := maps.Clone(values)
+
+	for name, value := range changes {
+		if value == nil {
+			delete(merged, name)
+		} else {
+			merged[name] = value
+		}
+	}
+
+	return merged
+}
+
+// newTestVerifier creates a TokenVerifier trusting the key under the key id "key-1"
+func newTestVerifier(t *testing.T, key *rsa.PrivateKey) *TokenVerifier {
+	t.Helper()
+
+	verifier, err := NewTokenVerifier(staticKeySet{"key-1": &key.PublicKey}, testIssuer, testAppId)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return verifier
+}
+
+func TestTokenVerifierVerify(t *testing.T) {
+	key := newTestSigningKey(t)
+	otherKey := newTestSigningKey(t)
+	verifier := newTestVerifier(t, key)
+	now := time.Now()
+
+	header := map[string]any{"alg": "RS256", "kid": "key-1", "typ": AccessTokenType}
+	claims := map[string]any{
+		"iss": testIssuer,
+		"sub": "user-1",
+		"aud": testAppId,
+		"exp": now.Add(time.Hour).Unix(),
+		"iat": now.Unix(),
+		"jti": "token-1",
+		"sid": "session-1",
+	}
+
+	valid := signTestJWT(t, key, header, claims)
+
+	// The payload of a token signed for another subject, keeping the header and signature of the valid token
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + strings.Split(signTestJWT(t, key, header, withChanges(claims, map[string]any{"sub": "admin"})), ".")[1] + "." + parts[2]
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"valid", valid, nil},
+		{"signed with another key", signTestJWT(t, otherKey, header, claims), ErrInvalidAuthToken},
+		{"tampered payload", tampered, ErrInvalidAuthToken},
+		{"alg none", signTestJWT(t, key, withChanges(header, map[string]any{"alg": "none"}), claims), ErrInvalidAuthToken},
+		{"alg HS256", signTestJWT(t, key, withChanges(header, map[string]any{"alg": "HS256"}), claims), ErrInvalidAuthToken},
+		{"alg ES256 with an RSA key", signTestJWT(t, key, withChanges(header, map[string]any{"alg": "ES256"}), claims), ErrInvalidAuthToken},
+		{"unknown key id", signTestJWT(t, key, withChanges(header, map[string]any{"kid": "key-2"}), claims), ErrInvalidAuthToken},
+		{"id token typ", signTestJWT(t, key, withChanges(header, map[string]any{"typ": "JWT"}), claims), ErrInvalidAuthToken},
+		{"no typ", signTestJWT(t, key, withChanges(header, map[string]any{"typ": nil}), claims), ErrInvalidAuthToken},
+		{"wrong audience", signTestJWT(t, key, header, withChanges(claims, map[string]any{"aud": "other-app"})), ErrInvalidAuthToken},
+		{"wrong issuer", signTestJWT(t, key, header, withChanges(claims, map[string]any{"iss": "https://evil.example.com"})), ErrInvalidAuthToken},
+		{"expired", signTestJWT(t, key, header, withChanges(claims, map[string]any{"exp": now.Add(-time.Hour).Unix()})), ErrAuthTokenExpired},
+		{"no expiry", signTestJWT(t, key, header, withChanges(claims, map[string]any{"exp": nil})), ErrInvalidAuthToken},
+		{"not valid yet", signTestJWT(t, key, header, withChanges(claims, map[string]any{"nbf": now.Add(time.Hour).Unix()})), ErrInvalidAuthToken},
+		{"id token claims", signTestJWT(t, key, header, withChanges(claims, map[string]any{"nonce": "nonce-1"})), ErrInvalidAuthToken},
+		{"no token or session id", signTestJWT(t, key, header, withChanges(claims, map[string]any{"jti": nil, "sid": nil})), nil},
+		{"not a jwt", "not-a-jwt", ErrInvalidAuthToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verified, err := verifier.Verify(context.Background(), tt.token)
+
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Verify returned error %v", err)
+				}
+
+				if verified.Subject != "user-1" {
+					t.Errorf("claims = %+v, want the subject of the token", verified)
+				}
+
+				return
+			}
+
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Verify error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	verified, err := verifier.Verify(context.Background(), valid)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if verified.TokenId != "token-1" || verified.SessionId != "session-1" {
+		t.Errorf("claims = %+v, want the token and session id of the token", verified)
+	}
+}
+
+func TestTokenVerifierVerifyIDToken(t *testing.T) {
+	key := newTestSigningKey(t)
+	verifier := newTestVerifier(t, key)
+	now := time.Now()
+
+	header := map[string]any{"alg": "RS256", "kid": "key-1", "typ": "JWT"}
+	claims := map[string]any{
+		"iss":   testIssuer,
+		"sub":   "user-1",
+		"aud":   testAppId,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+		"nonce": "nonce-1",
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		want  error
+	}{
+		{"valid", signTestJWT(t, key, header, claims), "nonce-1", nil},
+		{"wrong nonce", signTestJWT(t, key, header, claims), "nonce-2", ErrInvalidAuthToken},
+		{"no nonce claim", signTestJWT(t, key, header, withChanges(claims, map[string]any{"nonce": nil})), "nonce-1", ErrInvalidAuthToken},
+		{"access token typ", signTestJWT(t, key, withChanges(header, map[string]any{"typ": AccessTokenType}), claims), "nonce-1", ErrInvalidAuthToken},
+		{"wrong audience", signTestJWT(t, key, header, withChanges(claims, map[string]any{"aud": "other-app"})), "nonce-1", ErrInvalidAuthToken},
+		{"expired", signTestJWT(t, key, header, withChanges(claims, map[string]any{"exp": now.Add(-time.Hour).Unix()})), "nonce-1", ErrAuthTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.VerifyIDToken(context.Background(), tt.token, tt.nonce)
+
+			if tt.want == nil && err != nil {
+				t.Fatalf("VerifyIDToken returned error %v", err)
+			}
+
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("VerifyIDToken error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := verifier.VerifyIDToken(context.Background(), signTestJWT(t, key, header, claims), ""); err == nil {
+		t.Error("VerifyIDToken without an expected nonce returned no error")
+	}
+}
+
+func TestNewTokenVerifierRequiresIssuerAndAppId(t *testing.T) {
+	keys := staticKeySet{}
+
+	if _, err := NewTokenVerifier(keys, "", testAppId); err == nil {
+		t.Error("NewTokenVerifier without an issuer returned no error")
+	}
+
+	if _, err := NewTokenVerifier(keys, testIssuer, ""); err == nil {
+		t.Error("NewTokenVerifier without an application id returned no error")
+	}
+
+	if _, err := NewTokenVerifier(nil, testIssuer, testAppId); err == nil {
+		t.Error("NewTokenVerifier without a key set returned no error")
+	}
+}
@@ -0,0 +1,203 @@
+package idam
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTOTPDigits is the number of digits in a TOTP code, as used by common authenticator apps
+	DefaultTOTPDigits = 6
+	// DefaultTOTPPeriod is how long each TOTP code is valid for
+	DefaultTOTPPeriod = 30 * time.Second
+	// DefaultTOTPSkew is the number of periods either side of the current one whose codes are also accepted
+	DefaultTOTPSkew = 1
+	// The number of random bytes in a generated TOTP secret, the length recommended by RFC 4226
+	totpSecretSize = 20
+)
+
+// The encoding of TOTP secrets, unpadded base32 as expected by authenticator apps
+var totpSecretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP generates and verifies RFC 6238 time-based one-time passwords using HMAC-SHA1, entirely offline
+type TOTP struct {
+	secret []byte
+	// The number of digits in a code
+	digits int
+	// How long each code is valid for
+	period time.Duration
+	// The number of periods either side of the current one whose codes are also accepted, allowing for clock drift
+	skew int
+}
+
+// TOTPOption configures a TOTP created with NewTOTP
+type TOTPOption func(*TOTP) error
+
+// WithTOTPDigits sets the number of digits in a code, which must be between 6 and 8 (RFC 4226)
+func WithTOTPDigits(digits int) TOTPOption {
+	return func(totp *TOTP) error {
+		if digits < 6 || digits > 8 {
+			return fmt.Errorf("invalid TOTP digits %d - must be between 6 and 8", digits)
+		}
+
+		totp.digits = digits
+		return nil
+	}
+}
+
+// WithTOTPPeriod sets how long each code is valid for, which must be a whole number of seconds of at least one second
+func WithTOTPPeriod(period time.Duration) TOTPOption {
+	return func(totp *TOTP) error {
+		if period < time.Second || period%time.Second != 0 {
+			return fmt.Errorf("invalid TOTP period %s - must be a whole number of seconds of at least 1s", period)
+		}
+
+		totp.period = period
+		return nil
+	}
+}
+
+// WithTOTPSkew sets the number of periods either side of the current one whose codes are also accepted, which must not be negative
+func WithTOTPSkew(skew int) TOTPOption {
+	return func(totp *TOTP) error {
+		if skew < 0 {
+			return fmt.Errorf("invalid TOTP skew %d - must not be negative", skew)
+		}
+
+		totp.skew = skew
+		return nil
+	}
+}
+
+// NewTOTP creates a TOTP for the base32 encoded secret configured with the options,
+// using the default digits, period and skew unless they are set.
+// Spaces and lowercase letters in the secret are accepted, as authenticator apps display it grouped.
+// Usage: NewTOTP(secret, WithTOTPDigits(8), WithTOTPPeriod(60*time.Second))
+func NewTOTP(secret string, opts ...TOTPOption) (*TOTP, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	decoded, err := totpSecretEncoding.DecodeString(strings.TrimRight(secret, "="))
+
+	if err != nil || len(decoded) == 0 {
+		return nil, errors.New("invalid TOTP secret - must be base32 encoded")
+	}
+
+	totp := &TOTP{
+		secret: decoded,
+		digits: DefaultTOTPDigits,
+		period: DefaultTOTPPeriod,
+		skew:   DefaultTOTPSkew,
+	}
+
+	for _, opt := range opts {
+		if err = opt(totp); err != nil {
+			return nil, err
+		}
+	}
+
+	return totp, nil
+}
+
+// GenerateTOTPSecret returns a new random base32 encoded TOTP secret
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, totpSecretSize)
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("error generating TOTP secret - %v", err)
+	}
+
+	return totpSecretEncoding.EncodeToString(secret), nil
+}
+
+// URI returns the otpauth:// URI authenticator apps scan, usually as a QR code, to enroll the secret.
+// The digits and period are included when they differ from the defaults, so the app generates the same codes as Verify accepts.
+// Usage: totp.URI("My App", "user@example.com")
+func (totp *TOTP) URI(issuer string, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", totpSecretEncoding.EncodeToString(totp.secret))
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+
+	if totp.digits != DefaultTOTPDigits {
+		query.Set("digits", strconv.Itoa(totp.digits))
+	}
+
+	if totp.period != DefaultTOTPPeriod {
+		query.Set("period", strconv.Itoa(int(totp.period.Seconds())))
+	}
+
+	uri := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + accountName,
+		RawQuery: query.Encode(),
+	}
+
+	return uri.String()
+}
+
+// Code returns the code for the period containing the time
+func (totp *TOTP) Code(t time.Time) string {
+	return totp.codeForCounter(totp.counter(t))
+}
+
+// Verify reports whether the code is valid at the time, allowing for the skew periods of clock drift
+func (totp *TOTP) Verify(code string, t time.Time) bool {
+	code = strings.ReplaceAll(code, " ", "")
+
+	if len(code) != totp.digits {
+		return false
+	}
+
+	counter := totp.counter(t)
+	valid := false
+
+	// Every candidate is compared so the time taken does not reveal which period matched
+	for offset := -int64(totp.skew); offset <= int64(totp.skew); offset++ {
+		if counter+offset < 0 {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(totp.codeForCounter(counter+offset)), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+// counter returns the number of periods elapsed since the unix epoch
+func (totp *TOTP) counter(t time.Time) int64 {
+	return t.Unix() / int64(totp.period/time.Second)
+}
+
+// codeForCounter computes the RFC 4226 HOTP code for the counter
+func (totp *TOTP) codeForCounter(counter int64) string {
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, totp.secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation, the low nibble of the last byte selects four bytes of the digest
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+
+	for i := 0; i < totp.digits; i++ {
+		modulus *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totp.digits, value%modulus)
+}
@@ -0,0 +1,128 @@
+package idam
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+// The base32 encoding of the ASCII SHA1 seed "12345678901234567890" of RFC 6238 Appendix B
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	totp, err := NewTOTP(rfc6238Secret, WithTOTPDigits(8))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The SHA1 test vectors of RFC 6238 Appendix B
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		if got := totp.Code(time.Unix(tt.unix, 0)); got != tt.code {
+			t.Errorf("Code(%d) = %q, want %q", tt.unix, got, tt.code)
+		}
+
+		if !totp.Verify(tt.code, time.Unix(tt.unix, 0)) {
+			t.Errorf("Verify(%q, %d) = false, want true", tt.code, tt.unix)
+		}
+	}
+}
+
+func TestTOTPVerifySkew(t *testing.T) {
+	totp, err := NewTOTP(rfc6238Secret)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Unix(1111111111, 0)
+	code := totp.Code(now)
+
+	if !totp.Verify(code, now.Add(DefaultTOTPPeriod)) {
+		t.Error("Verify rejected the code of the previous period")
+	}
+
+	if totp.Verify(code, now.Add(2*DefaultTOTPPeriod)) {
+		t.Error("Verify accepted the code of two periods ago")
+	}
+}
+
+func TestNewTOTPRejectsInvalidOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  TOTPOption
+	}{
+		{"too few digits", WithTOTPDigits(5)},
+		{"too many digits", WithTOTPDigits(10)},
+		{"zero period", WithTOTPPeriod(0)},
+		{"sub-second period", WithTOTPPeriod(500 * time.Millisecond)},
+		{"fractional period", WithTOTPPeriod(1500 * time.Millisecond)},
+		{"negative skew", WithTOTPSkew(-1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewTOTP(rfc6238Secret, tt.opt); err == nil {
+				t.Error("NewTOTP returned no error")
+			}
+		})
+	}
+}
+
+func TestTOTPURI(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      []TOTPOption
+		wantQuery url.Values
+	}{
+		{"defaults", nil, url.Values{
+			"secret":    {rfc6238Secret},
+			"issuer":    {"My App"},
+			"algorithm": {"SHA1"},
+		}},
+		{"configured", []TOTPOption{WithTOTPDigits(8), WithTOTPPeriod(60 * time.Second)}, url.Values{
+			"secret":    {rfc6238Secret},
+			"issuer":    {"My App"},
+			"algorithm": {"SHA1"},
+			"digits":    {"8"},
+			"period":    {"60"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The secret is accepted grouped and lowercase, the URI has it as authenticator apps expect
+			totp, err := NewTOTP("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", tt.opts...)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			uri, err := url.Parse(totp.URI("My App", "user@example.com"))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if uri.Scheme != "otpauth" || uri.Host != "totp" || uri.Path != "/My App:user@example.com" {
+				t.Errorf("uri = %s, want the totp label of the issuer and account", uri)
+			}
+
+			if got := uri.Query(); got.Encode() != tt.wantQuery.Encode() {
+				t.Errorf("query = %v, want %v", got, tt.wantQuery)
+			}
+		})
+	}
+}
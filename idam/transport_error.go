@@ -0,0 +1,87 @@
+package idam
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// RequestIdHeader is the response header carrying the id the IDAM service (or a proxy in front of it) assigned to the request
+	RequestIdHeader = "X-Request-Id"
+	// The maximum number of bytes of the response body kept in a TransportError
+	maxTransportErrorBodyLength = 512
+)
+
+// TransportError is returned when a response from the IDAM service could not be understood,
+// e.g. a non-success status with a body that is not an ErrorResponse, such as an HTML page from a proxy.
+// The underlying error is available through errors.Unwrap.
+type TransportError struct {
+	// The http status code of the response
+	StatusCode int
+	// The http method of the request
+	Method string
+	// The url of the request
+	URL string
+	// The value of the RequestIdHeader of the response, if any
+	RequestId string
+	// The start of the response body, truncated to a fixed length
+	Body string
+	// The error encountered while decoding the response
+	Err error
+}
+
+// newTransportError creates a TransportError describing the response to the request.
+// The request is the one sent, as the response's Request field is not set by a Doer building its own response.
+func newTransportError(request *http.Request, response *http.Response, body []byte, err error) *TransportError {
+	return &TransportError{
+		StatusCode: response.StatusCode,
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		RequestId:  response.Header.Get(RequestIdHeader),
+		Body:       truncateBody(body),
+		Err:        err,
+	}
+}
+
+// Error returns a description of the failed request including its status, request id and body snippet
+func (err *TransportError) Error() string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "unexpected response from idam service - %s %s returned status %d", err.Method, err.URL, err.StatusCode)
+
+	if err.RequestId != "" {
+		fmt.Fprintf(&builder, " (request id %s)", err.RequestId)
+	}
+
+	if err.Err != nil {
+		fmt.Fprintf(&builder, ": %v", err.Err)
+	}
+
+	if err.Body != "" {
+		fmt.Fprintf(&builder, ": %q", err.Body)
+	}
+
+	return builder.String()
+}
+
+// Unwrap returns the underlying decoding error
+func (err *TransportError) Unwrap() error {
+	return err.Err
+}
+
+// truncateBody returns the body as a string cut to maxTransportErrorBodyLength without splitting a character
+func truncateBody(body []byte) string {
+	if len(body) <= maxTransportErrorBodyLength {
+		return string(body)
+	}
+
+	cut := maxTransportErrorBodyLength
+
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+
+	return string(body[:cut]) + "..."
+}
@@ -0,0 +1,82 @@
+package idam
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestTransportErrorPreservesTheFailedResponse(t *testing.T) {
+	server := newTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(RequestIdHeader, "request-1")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html>bad gateway</html>"))
+	}))
+
+	_, err := newTestClient(t, server).GetCurrentUser("token")
+
+	var transportErr *TransportError
+
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("error = %v, want a *TransportError", err)
+	}
+
+	want := TransportError{
+		StatusCode: http.StatusBadGateway,
+		Method:     http.MethodGet,
+		URL:        server.URL + CurrentUserUrlSuffix,
+		RequestId:  "request-1",
+		Body:       "<html>bad gateway</html>",
+	}
+
+	transportErr.Err = nil
+
+	if *transportErr != want {
+		t.Errorf("transport error = %+v, want %+v", *transportErr, want)
+	}
+}
+
+func TestTransportErrorOfAResponseBuiltByMiddleware(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+
+	// The response is answered without reaching the server, so its Request field is not set
+	client := newTestClient(t, server, WithMiddleware(func(next Doer) Doer {
+		return DoerFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader("unavailable")),
+			}, nil
+		})
+	}))
+
+	_, err := client.GetCurrentUser("token")
+
+	var transportErr *TransportError
+
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("error = %v, want a *TransportError", err)
+	}
+
+	if transportErr.Method != http.MethodGet || transportErr.URL != server.URL+CurrentUserUrlSuffix {
+		t.Errorf("request = %s %s, want the method and url of the request sent", transportErr.Method, transportErr.URL)
+	}
+}
+
+func TestTransportErrorTruncatesTheBody(t *testing.T) {
+	server := newTestServer(t, respondWith(http.StatusBadGateway, strings.Repeat("é", maxTransportErrorBodyLength)))
+
+	_, err := newTestClient(t, server).GetCurrentUser("token")
+
+	var transportErr *TransportError
+
+	if !errors.As(err, &transportErr) {
+		t.Fatalf("error = %v, want a *TransportError", err)
+	}
+
+	if want := strings.Repeat("é", maxTransportErrorBodyLength/2) + "..."; transportErr.Body != want {
+		t.Errorf("body = %q, want the body cut to %d bytes", transportErr.Body, maxTransportErrorBodyLength)
+	}
+}
@@ -13,8 +13,13 @@ type User struct {
 	Username     string       `json:"username"`
 	Email        string       `json:"email"`
 	Verified     bool         `json:"verified"`
+	Disabled     bool         `json:"disabled"`
+	MFAEnabled   bool         `json:"mfa_enabled"`
 	Type         IdamUserType `json:"type"`
 	Provider     string       `json:"provider"`
 	CreatedAtUTC time.Time    `json:"created_at_utc"`
 	Features     []string     `json:"features"`
+	Roles        []Role       `json:"roles"`
+	// The permissions granted to the user by their roles
+	Permissions []Permission `json:"permissions"`
 }
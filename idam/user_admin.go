@@ -0,0 +1,116 @@
+package idam
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// ServiceCredentials are the credentials a back-office service uses to authenticate with the IDAM admin API
+type ServiceCredentials struct {
+	ClientId     string
+	ClientSecret string
+}
+
+// UserListOptions controls the paging and filtering of a user listing
+type UserListOptions struct {
+	// The 1-based page to return, the first page is returned if zero
+	Page int
+	// The number of users per page, the service default is used if zero
+	PageSize int
+	// Only users whose username or email contains the search term are returned if set
+	Search string
+}
+
+// query encodes the options as url query parameters
+func (options UserListOptions) query() url.Values {
+	query := pageQuery(options.Page, options.PageSize)
+
+	if options.Search != "" {
+		query.Set("search", options.Search)
+	}
+
+	return query
+}
+
+// pageQuery encodes the paging options shared by the admin listings as url query parameters, zero values are omitted
+func pageQuery(page int, pageSize int) url.Values {
+	query := url.Values{}
+
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+
+	if pageSize > 0 {
+		query.Set("page_size", strconv.Itoa(pageSize))
+	}
+
+	return query
+}
+
+// withQuery appends the query to the url suffix, the url suffix is returned as is if the query is empty
+func withQuery(urlSuffix string, query url.Values) string {
+	if len(query) == 0 {
+		return urlSuffix
+	}
+
+	return urlSuffix + "?" + query.Encode()
+}
+
+// UserListResponse is a page of users returned by the admin user listing endpoint
+type UserListResponse struct {
+	Users      []User `json:"users"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalCount int    `json:"total_count"`
+}
+
+// HasNextPage reports whether there are more users after this page
+func (response *UserListResponse) HasNextPage() bool {
+	return response.Page*response.PageSize < response.TotalCount
+}
+
+// UserUpdateRequest is the request object for the admin user update endpoint
+// Only the fields that are set are updated.
+type UserUpdateRequest struct {
+	Username *string `json:"username,omitempty"`
+	Email    *string `json:"email,omitempty"`
+}
+
+// Validate validates the user update request
+func (request *UserUpdateRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the user update request, returning the errors of each field
+func (request *UserUpdateRequest) ValidateFields() ValidationErrors {
+	var validationErrors ValidationErrors
+
+	if request.Username == nil && request.Email == nil {
+		validationErrors = append(validationErrors, ValidationError{
+			Rule:    ValidationRuleRequireOneOf,
+			Message: "at least one of username or email must be provided",
+			Params:  map[string]string{"fields": "username, email"},
+		})
+	}
+
+	// The username and email must follow the same rules as registration
+	if request.Username != nil {
+		validationErrors = append(validationErrors, validateUsername(*request.Username)...)
+	}
+
+	if request.Email != nil {
+		validationErrors = append(validationErrors, validateEmail(*request.Email)...)
+	}
+
+	return validationErrors
+}
+
+// UserFeaturesRequest is the request object for the admin endpoint replacing a user's features
+type UserFeaturesRequest struct {
+	Features []string `json:"features"`
+}
+
+// UserRolesRequest is the request object for the admin endpoint replacing a user's roles
+type UserRolesRequest struct {
+	Roles []Role `json:"roles"`
+}
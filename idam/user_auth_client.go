@@ -1,20 +1,20 @@
 package idam
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
-	"fmt"
+	"context"
 	"net/http"
 	"net/url"
-	"strings"
+	"sync"
 )
 
 // A client for making http calls to the IDAM service's user account serving endpoints
 // This client should be used for user facing calls to IDAM.
 type UserAuthClient struct {
-	httpClient *http.Client
-	baseUrl    string
+	executor requestExecutor
+	metadata *ProviderMetadata
+
+	keysMu sync.Mutex
+	keys   *JWKSKeySet
 }
 
 const (
@@ -24,149 +24,99 @@ const (
 	UserLogoutUrlSuffix               = "/api/idam/user-account/logout"
 	InitiateUserPasswordResetUrl      = "/api/idam/user-account/applications/:appId/initiate-password-reset"
 	ExecuteUserPasswordResetUrl       = "/api/idam/user-account/applications/:appId/execute-password-reset"
+	UserTokenRefreshUrlSuffix         = "/api/idam/user-account/applications/:appId/refresh"
+	UserTokenValidationUrlSuffix      = "/api/idam/user-account/validate-token"
+	CurrentUserUrlSuffix              = "/api/idam/user-account/me"
+	CurrentUserUsernameUrlSuffix      = "/api/idam/user-account/me/username"
+	CurrentUserEmailChangeUrlSuffix   = "/api/idam/user-account/applications/:appId/me/email"
+	ConfirmUserEmailChangeUrlSuffix   = "/api/idam/user-account/applications/:appId/confirm-email-change"
+	CurrentUserPasswordUrlSuffix      = "/api/idam/user-account/me/password"
+	ResendUserVerificationUrlSuffix   = "/api/idam/user-account/applications/:appId/resend-verification"
 )
 
-// Function to create a new IdamAuthService
-func NewUserAuthClient(httpClient *http.Client, baseUrl string) *UserAuthClient {
-	return &UserAuthClient{
-		httpClient: httpClient,
-		baseUrl:    baseUrl,
-	}
-}
-
-// Register method to call the user account registration endpoint
-func (client *UserAuthClient) Register(appId string, request *UserRegistrationRequest) (*UserRegistrationResponse, error) {
-	urlSuffix := strings.Replace(UserRegistrationAccountUrlSuffix, ":appId", appId, 1)
-
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(urlSuffix)
+// New creates a UserAuthClient for the IDAM service at the baseUrl configured with the options.
+// An error is returned if the baseUrl is not an absolute http or https url or an option is invalid.
+// Usage: New("https://idam.example.com", WithTimeout(10*time.Second), WithDefaultAppID("my-app"))
+func New(baseUrl string, opts ...ClientOption) (*UserAuthClient, error) {
+	base, err := parseBaseUrl(baseUrl)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
-
-	requestBodyBytes, err := json.Marshal(request)
+	options, err := applyClientOptions(opts)
 
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPost, resolvedURL.String(), bytes.NewReader(requestBodyBytes))
-	if err != nil {
-		return nil, err
-	}
-
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
+	return &UserAuthClient{
+		executor: newRequestExecutor(base, nil, options),
+		metadata: options.metadata,
+	}, nil
+}
 
-	// Call the IDAM service /api/idam/user-account/applications/:appId/register endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
+// Function to create a new IdamAuthService
+// Every request made by the client is sent through the provided middleware before reaching the httpClient.
+// Prefer New, which reports an invalid baseUrl at construction rather than on every call.
+func NewUserAuthClient(httpClient *http.Client, baseUrl string, middleware ...Middleware) *UserAuthClient {
+	base, err := url.Parse(baseUrl)
 
-	if err != nil {
-		return nil, err
+	return &UserAuthClient{
+		executor: newRequestExecutor(base, err, clientOptions{
+			httpClient: httpClient,
+			middleware: middleware,
+		}),
 	}
+}
 
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusCreated {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
-
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
-
-		if err != nil {
-			return nil, fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
-
-		return nil, &errorResponse
-	}
+// Register method to call the user account registration endpoint
+func (client *UserAuthClient) Register(appId string, request *UserRegistrationRequest) (*UserRegistrationResponse, error) {
+	return client.RegisterContext(context.Background(), appId, request)
+}
 
+// RegisterContext method to call the user account registration endpoint using the provided context
+func (client *UserAuthClient) RegisterContext(ctx context.Context, appId string, request *UserRegistrationRequest) (*UserRegistrationResponse, error) {
 	var usrRegResponse UserRegistrationResponse
 
-	err = json.NewDecoder(response.Body).Decode(&usrRegResponse)
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      client.executor.appUrlSuffix(UserRegistrationAccountUrlSuffix, appId),
+		body:           request,
+		expectedStatus: http.StatusCreated,
+		response:       &usrRegResponse,
+		operation:      "register user",
+	})
 
 	if err != nil {
-		return nil, errors.New("error decoding register user response body from idam service")
+		return nil, err
 	}
 
 	return &usrRegResponse, nil
 }
 
 // Login method to call the user account login endpoint
+// If the user has multi-factor authentication enabled the response has an MFAChallenge instead of tokens,
+// which is completed with VerifyMFA.
 func (client *UserAuthClient) Login(appId string, request *UserLoginRequest) (*UserLoginResponse, error) {
-	urlSuffix := strings.Replace(UserLoginUrlSuffix, ":appId", appId, 1)
-
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(urlSuffix)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
-
-	requestBodyBytes, err := json.Marshal(request)
-
-	if err != nil {
-		return nil, err
-	}
-
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPost, resolvedURL.String(), bytes.NewReader(requestBodyBytes))
-	if err != nil {
-		return nil, err
-	}
-
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
-
-	// Call the IDAM service /api/idam/user-account/applications/:appId/login endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
-
-	if err != nil {
-		return nil, err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
-
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
-
-		if err != nil {
-			return nil, fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
-
-		return nil, &errorResponse
-	}
+	return client.LoginContext(context.Background(), appId, request)
+}
 
+// LoginContext method to call the user account login endpoint using the provided context
+func (client *UserAuthClient) LoginContext(ctx context.Context, appId string, request *UserLoginRequest) (*UserLoginResponse, error) {
 	var loginResponse UserLoginResponse
 
-	err = json.NewDecoder(response.Body).Decode(&loginResponse)
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      client.executor.appUrlSuffix(UserLoginUrlSuffix, appId),
+		body:           request,
+		expectedStatus: http.StatusOK,
+		response:       &loginResponse,
+		operation:      "login user",
+	})
 
 	if err != nil {
-		return nil, errors.New("error decoding login user response body from idam service")
+		return nil, err
 	}
 
 	return &loginResponse, nil
@@ -174,243 +124,248 @@ func (client *UserAuthClient) Login(appId string, request *UserLoginRequest) (*U
 
 // VerifyAccount method to call the user account verify account endpoint
 func (client *UserAuthClient) VerifyAccount(appId string, request *UserAccountVerificationRequest) error {
-	urlSuffix := strings.Replace(UserAccountVerifyAccountUrlSuffix, ":appId", appId, 1)
-
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
-
-	if err != nil {
-		return err
-	}
-
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(urlSuffix)
-
-	if err != nil {
-		return err
-	}
-
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
-
-	requestBodyBytes, err := json.Marshal(request)
-
-	if err != nil {
-		return err
-	}
-
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPut, resolvedURL.String(), bytes.NewReader(requestBodyBytes))
-	if err != nil {
-		return err
-	}
-
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
-
-	// Call the IDAM service /api/idam/user-account/applications/:appId/verify-account endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusNoContent {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
-
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
-
-		if err != nil {
-			return fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
-
-		return &errorResponse
-	}
+	return client.VerifyAccountContext(context.Background(), appId, request)
+}
 
-	return nil
+// VerifyAccountContext method to call the user account verify account endpoint using the provided context
+func (client *UserAuthClient) VerifyAccountContext(ctx context.Context, appId string, request *UserAccountVerificationRequest) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      client.executor.appUrlSuffix(UserAccountVerifyAccountUrlSuffix, appId),
+		body:           request,
+		expectedStatus: http.StatusNoContent,
+		operation:      "verify account",
+	})
 }
 
 // Logout method to call the user account logout endpoint
 // If the authToken doesnt start with "Bearer " then it will be prepeneded and added to the Authorization header of the request
 func (client *UserAuthClient) Logout(authToken string) error {
+	return client.LogoutContext(context.Background(), authToken)
+}
 
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
-
-	if err != nil {
-		return err
-	}
-
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(UserLogoutUrlSuffix)
-
-	if err != nil {
-		return err
-	}
-
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
-
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPost, resolvedURL.String(), nil)
-	if err != nil {
-		return err
-	}
-
-	// If the authToken doesnt start with "Bearer " then add it
-	if !strings.HasPrefix(authToken, "Bearer ") {
-		authToken = "Bearer " + authToken
-	}
-
-	// Set the Authorization header to the token
-	req.Header.Set("Authorization", authToken)
-
-	// Call the IDAM service /api/idam/user-account/logout endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
-
-	if err != nil {
-		return err
-	}
-
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
-
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
-
-		if err != nil {
-			return fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
-
-		return &errorResponse
-	}
-
-	return nil
+// LogoutContext method to call the user account logout endpoint using the provided context
+func (client *UserAuthClient) LogoutContext(ctx context.Context, authToken string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      UserLogoutUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusOK,
+		operation:      "logout user",
+	})
 }
 
 // InitiatePasswordReset method to call the user account initiate password reset endpoint
 func (client *UserAuthClient) InitiatePasswordReset(appId string, request *UserPasswordResetInitiationRequest) error {
-	urlSuffix := strings.Replace(InitiateUserPasswordResetUrl, ":appId", appId, 1)
+	return client.InitiatePasswordResetContext(context.Background(), appId, request)
+}
 
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
+// InitiatePasswordResetContext method to call the user account initiate password reset endpoint using the provided context
+func (client *UserAuthClient) InitiatePasswordResetContext(ctx context.Context, appId string, request *UserPasswordResetInitiationRequest) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      client.executor.appUrlSuffix(InitiateUserPasswordResetUrl, appId),
+		body:           request,
+		expectedStatus: http.StatusOK,
+		operation:      "initiate password reset",
+	})
+}
 
-	if err != nil {
-		return err
-	}
+// ExecutePasswordReset method to call the user account execute password reset endpoint
+func (client *UserAuthClient) ExecutePasswordReset(appId string, request *UserPasswordResetExecutionRequest) error {
+	return client.ExecutePasswordResetContext(context.Background(), appId, request)
+}
 
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(urlSuffix)
+// ExecutePasswordResetContext method to call the user account execute password reset endpoint using the provided context
+func (client *UserAuthClient) ExecutePasswordResetContext(ctx context.Context, appId string, request *UserPasswordResetExecutionRequest) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      client.executor.appUrlSuffix(ExecuteUserPasswordResetUrl, appId),
+		body:           request,
+		expectedStatus: http.StatusNoContent,
+		operation:      "execute password reset",
+	})
+}
 
-	if err != nil {
-		return err
-	}
+// Refresh method to call the user account token refresh endpoint
+// The refresh token from a previous UserLoginResponse is exchanged for a new access token.
+func (client *UserAuthClient) Refresh(appId string, refreshToken string) (*UserLoginResponse, error) {
+	return client.RefreshContext(context.Background(), appId, refreshToken)
+}
 
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
+// RefreshContext method to call the user account token refresh endpoint using the provided context
+func (client *UserAuthClient) RefreshContext(ctx context.Context, appId string, refreshToken string) (*UserLoginResponse, error) {
+	var loginResponse UserLoginResponse
 
-	requestBodyBytes, err := json.Marshal(request)
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      client.executor.appUrlSuffix(UserTokenRefreshUrlSuffix, appId),
+		body:           &UserTokenRefreshRequest{RefreshToken: refreshToken},
+		expectedStatus: http.StatusOK,
+		response:       &loginResponse,
+		operation:      "refresh token",
+	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPost, resolvedURL.String(), bytes.NewReader(requestBodyBytes))
-	if err != nil {
-		return err
-	}
+	return &loginResponse, nil
+}
 
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
+// ValidateToken method to call the user account token validation endpoint
+// The User the token was issued to is returned if the token is valid.
+func (client *UserAuthClient) ValidateToken(authToken string) (*User, error) {
+	return client.ValidateTokenContext(context.Background(), authToken)
+}
+
+// ValidateTokenContext method to call the user account token validation endpoint using the provided context
+func (client *UserAuthClient) ValidateTokenContext(ctx context.Context, authToken string) (*User, error) {
+	var user User
 
-	// Call the IDAM service /api/idam/user-account/initiate-password-reset endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      UserTokenValidationUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusOK,
+		response:       &user,
+		operation:      "validate token",
+		retryable:      true,
+	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
-
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
-
-		if err != nil {
-			return fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
-
-		return &errorResponse
-	}
+	return &user, nil
+}
 
-	return nil
+// GetCurrentUser method to call the current user endpoint
+// The User the authToken was issued to is returned.
+func (client *UserAuthClient) GetCurrentUser(authToken string) (*User, error) {
+	return client.GetCurrentUserContext(context.Background(), authToken)
 }
 
-// ExecutePasswordReset method to call the user account execute password reset endpoint
-func (client *UserAuthClient) ExecutePasswordReset(appId string, request *UserPasswordResetExecutionRequest) error {
-	urlSuffix := strings.Replace(ExecuteUserPasswordResetUrl, ":appId", appId, 1)
+// GetCurrentUserContext method to call the current user endpoint using the provided context
+func (client *UserAuthClient) GetCurrentUserContext(ctx context.Context, authToken string) (*User, error) {
+	var user User
 
-	// Parse the base URL
-	base, err := url.Parse(client.baseUrl)
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      CurrentUserUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusOK,
+		response:       &user,
+		operation:      "get current user",
+		retryable:      true,
+	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Parse the suffix as a URL
-	suffix, err := url.Parse(urlSuffix)
-
-	if err != nil {
-		return err
-	}
+	return &user, nil
+}
 
-	// Resolve to correct URL
-	resolvedURL := base.ResolveReference(suffix)
+// UpdateUsername method to call the current user username update endpoint
+// The updated User is returned. A DataConflict error is returned if the username is taken.
+func (client *UserAuthClient) UpdateUsername(authToken string, request *UserUsernameUpdateRequest) (*User, error) {
+	return client.UpdateUsernameContext(context.Background(), authToken, request)
+}
 
-	requestBodyBytes, err := json.Marshal(request)
+// UpdateUsernameContext method to call the current user username update endpoint using the provided context
+func (client *UserAuthClient) UpdateUsernameContext(ctx context.Context, authToken string, request *UserUsernameUpdateRequest) (*User, error) {
+	var user User
 
-	if err != nil {
-		return err
-	}
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      CurrentUserUsernameUrlSuffix,
+		authToken:      authToken,
+		body:           request,
+		expectedStatus: http.StatusOK,
+		response:       &user,
+		operation:      "update username",
+	})
 
-	// Create a new POST request
-	req, err := http.NewRequest(http.MethodPut, resolvedURL.String(), bytes.NewReader(requestBodyBytes))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Set the content type header
-	req.Header.Set("Content-Type", "application/json")
+	return &user, nil
+}
 
-	// Call the IDAM service /api/idam/user-account/execute-password-reset endpoint and validate the token via http
-	response, err := client.httpClient.Do(req)
+// RequestEmailChange method to call the current user email change endpoint
+// A verification token is sent to the new email address. The user's email is not changed until
+// the token is confirmed with ConfirmEmailChange. A DataConflict error is returned if the email is taken.
+func (client *UserAuthClient) RequestEmailChange(appId string, authToken string, request *UserEmailChangeRequest) error {
+	return client.RequestEmailChangeContext(context.Background(), appId, authToken, request)
+}
 
-	if err != nil {
-		return err
-	}
+// RequestEmailChangeContext method to call the current user email change endpoint using the provided context
+func (client *UserAuthClient) RequestEmailChangeContext(ctx context.Context, appId string, authToken string, request *UserEmailChangeRequest) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      client.executor.appUrlSuffix(CurrentUserEmailChangeUrlSuffix, appId),
+		authToken:      authToken,
+		body:           request,
+		expectedStatus: http.StatusAccepted,
+		operation:      "request email change",
+	})
+}
 
-	defer response.Body.Close()
+// ConfirmEmailChange method to call the email change confirmation endpoint
+// The user's email is replaced with the new address once the verification token sent to it is confirmed.
+func (client *UserAuthClient) ConfirmEmailChange(appId string, request *UserEmailChangeConfirmationRequest) error {
+	return client.ConfirmEmailChangeContext(context.Background(), appId, request)
+}
 
-	if response.StatusCode != http.StatusNoContent {
-		// UnMarhsal the response body into an ErrorResponse object
-		var errorResponse ErrorResponse
+// ConfirmEmailChangeContext method to call the email change confirmation endpoint using the provided context
+func (client *UserAuthClient) ConfirmEmailChangeContext(ctx context.Context, appId string, request *UserEmailChangeConfirmationRequest) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      client.executor.appUrlSuffix(ConfirmUserEmailChangeUrlSuffix, appId),
+		body:           request,
+		expectedStatus: http.StatusNoContent,
+		operation:      "confirm email change",
+	})
+}
 
-		err = json.NewDecoder(response.Body).Decode(&errorResponse)
+// ChangePassword method to call the current user password change endpoint
+// An InvalidCredentials error is returned if the currentPassword is wrong.
+func (client *UserAuthClient) ChangePassword(authToken string, currentPassword string, newPassword string) error {
+	return client.ChangePasswordContext(context.Background(), authToken, currentPassword, newPassword)
+}
 
-		if err != nil {
-			return fmt.Errorf("error decoding response body from idam service - %v", err)
-		}
+// ChangePasswordContext method to call the current user password change endpoint using the provided context
+func (client *UserAuthClient) ChangePasswordContext(ctx context.Context, authToken string, currentPassword string, newPassword string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:    http.MethodPut,
+		urlSuffix: CurrentUserPasswordUrlSuffix,
+		authToken: authToken,
+		body: &UserPasswordChangeRequest{
+			CurrentPassword: currentPassword,
+			NewPassword:     newPassword,
+		},
+		expectedStatus: http.StatusNoContent,
+		operation:      "change password",
+	})
+}
 
-		return &errorResponse
-	}
+// ResendVerification method to call the user account resend verification endpoint
+// A new verification token is sent to the email address if it belongs to an unverified user.
+// To avoid revealing which email addresses are registered, the call succeeds whether or not
+// the email exists or has already been verified.
+func (client *UserAuthClient) ResendVerification(appId string, email string) error {
+	return client.ResendVerificationContext(context.Background(), appId, email)
+}
 
-	return nil
+// ResendVerificationContext method to call the user account resend verification endpoint using the provided context
+func (client *UserAuthClient) ResendVerificationContext(ctx context.Context, appId string, email string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      client.executor.appUrlSuffix(ResendUserVerificationUrlSuffix, appId),
+		body:           &UserVerificationResendRequest{Email: email},
+		expectedStatus: http.StatusAccepted,
+		operation:      "resend verification",
+	})
 }
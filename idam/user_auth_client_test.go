@@ -0,0 +1,86 @@
+package idam
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// contextCalls calls each context-aware UserAuthClient method with the context
+var contextCalls = []struct {
+	name string
+	call func(ctx context.Context, client *UserAuthClient) error
+}{
+	{"RegisterContext", func(ctx context.Context, client *UserAuthClient) error {
+		_, err := client.RegisterContext(ctx, "app", &UserRegistrationRequest{})
+		return err
+	}},
+	{"LoginContext", func(ctx context.Context, client *UserAuthClient) error {
+		_, err := client.LoginContext(ctx, "app", &UserLoginRequest{})
+		return err
+	}},
+	{"VerifyAccountContext", func(ctx context.Context, client *UserAuthClient) error {
+		return client.VerifyAccountContext(ctx, "app", &UserAccountVerificationRequest{})
+	}},
+	{"LogoutContext", func(ctx context.Context, client *UserAuthClient) error {
+		return client.LogoutContext(ctx, "token")
+	}},
+	{"InitiatePasswordResetContext", func(ctx context.Context, client *UserAuthClient) error {
+		return client.InitiatePasswordResetContext(ctx, "app", &UserPasswordResetInitiationRequest{})
+	}},
+	{"ExecutePasswordResetContext", func(ctx context.Context, client *UserAuthClient) error {
+		return client.ExecutePasswordResetContext(ctx, "app", &UserPasswordResetExecutionRequest{})
+	}},
+	{"RefreshContext", func(ctx context.Context, client *UserAuthClient) error {
+		_, err := client.RefreshContext(ctx, "app", "refresh-1")
+		return err
+	}},
+	{"ValidateTokenContext", func(ctx context.Context, client *UserAuthClient) error {
+		_, err := client.ValidateTokenContext(ctx, "token")
+		return err
+	}},
+}
+
+func TestContextVariantsSendRequestsWithTheContext(t *testing.T) {
+	for _, tt := range contextCalls {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+
+			var got any
+
+			client := newTestClient(t, server, WithMiddleware(func(next Doer) Doer {
+				return DoerFunc(func(req *http.Request) (*http.Response, error) {
+					got = req.Context().Value(testContextKey{})
+					return next.Do(req)
+				})
+			}))
+
+			tt.call(context.WithValue(context.Background(), testContextKey{}, "value"), client)
+
+			if got != "value" {
+				t.Errorf("request context value = %v, want the value of the caller's context", got)
+			}
+		})
+	}
+}
+
+func TestContextVariantsStopWhenTheContextIsCancelled(t *testing.T) {
+	for _, tt := range contextCalls {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusOK, `{}`))
+			client := newTestClient(t, server)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			if err := tt.call(ctx, client); !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want %v", err, context.Canceled)
+			}
+
+			if got := server.requestCount(); got != 0 {
+				t.Errorf("requests = %d, want none", got)
+			}
+		})
+	}
+}
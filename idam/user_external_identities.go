@@ -0,0 +1,186 @@
+package idam
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	ExternalLoginStartUrlSuffix    = "/api/idam/user-account/applications/:appId/external/:provider/start"
+	ExternalLoginCallbackUrlSuffix = "/api/idam/user-account/applications/:appId/external/:provider/callback"
+	CurrentUserIdentitiesUrlSuffix = "/api/idam/user-account/me/identities"
+	CurrentUserIdentityUrlSuffix   = "/api/idam/user-account/me/identities/:provider"
+)
+
+// StartExternalLogin method to call the external login start endpoint
+// A state and PKCE code verifier are generated for the login, the user should then be redirected to the
+// returned login's AuthorizationURL. The same flow is used to link an identity with LinkExternalIdentity.
+// ValidationErrors are returned without calling the service if the provider is empty or a dot segment.
+func (client *UserAuthClient) StartExternalLogin(appId string, provider string, redirectURI string) (*ExternalLogin, error) {
+	return client.StartExternalLoginContext(context.Background(), appId, provider, redirectURI)
+}
+
+// StartExternalLoginContext method to call the external login start endpoint using the provided context
+func (client *UserAuthClient) StartExternalLoginContext(ctx context.Context, appId string, provider string, redirectURI string) (*ExternalLogin, error) {
+	urlSuffix, err := substitutePathParams(ExternalLoginStartUrlSuffix, client.executor.appPathParam(appId), pathParam{":provider", provider})
+
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := GenerateState()
+
+	if err != nil {
+		return nil, err
+	}
+
+	pkce, err := GeneratePKCE()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var startResponse ExternalLoginStartResponse
+
+	err = client.executor.execute(ctx, apiRequest{
+		method:    http.MethodPost,
+		urlSuffix: urlSuffix,
+		body: &ExternalLoginStartRequest{
+			RedirectURI:         redirectURI,
+			State:               state,
+			CodeChallenge:       pkce.Challenge,
+			CodeChallengeMethod: pkce.Method,
+		},
+		expectedStatus: http.StatusOK,
+		response:       &startResponse,
+		operation:      "start external login",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExternalLogin{
+		Provider:         provider,
+		AuthorizationURL: startResponse.AuthorizationURL,
+		RedirectURI:      redirectURI,
+		State:            state,
+		CodeVerifier:     pkce.Verifier,
+	}, nil
+}
+
+// CompleteExternalLogin method to call the external login callback endpoint
+// The request is built from the provider's redirect with ExternalLogin.CallbackRequest.
+// A user is registered for the external identity if it is not linked to one already.
+func (client *UserAuthClient) CompleteExternalLogin(appId string, provider string, request *ExternalLoginCallbackRequest) (*UserLoginResponse, error) {
+	return client.CompleteExternalLoginContext(context.Background(), appId, provider, request)
+}
+
+// CompleteExternalLoginContext method to call the external login callback endpoint using the provided context
+func (client *UserAuthClient) CompleteExternalLoginContext(ctx context.Context, appId string, provider string, request *ExternalLoginCallbackRequest) (*UserLoginResponse, error) {
+	urlSuffix, err := substitutePathParams(ExternalLoginCallbackUrlSuffix, client.executor.appPathParam(appId), pathParam{":provider", provider})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var loginResponse UserLoginResponse
+
+	err = client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      urlSuffix,
+		body:           request,
+		expectedStatus: http.StatusOK,
+		response:       &loginResponse,
+		operation:      "complete external login",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &loginResponse, nil
+}
+
+// ListExternalIdentities method to call the current user identities endpoint
+func (client *UserAuthClient) ListExternalIdentities(authToken string) ([]ExternalIdentity, error) {
+	return client.ListExternalIdentitiesContext(context.Background(), authToken)
+}
+
+// ListExternalIdentitiesContext method to call the current user identities endpoint using the provided context
+func (client *UserAuthClient) ListExternalIdentitiesContext(ctx context.Context, authToken string) ([]ExternalIdentity, error) {
+	var identities []ExternalIdentity
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      CurrentUserIdentitiesUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusOK,
+		response:       &identities,
+		operation:      "list external identities",
+		retryable:      true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+// LinkExternalIdentity method to call the current user link identity endpoint
+// The request is built from a login started with StartExternalLogin, linking the provider account to the current user.
+func (client *UserAuthClient) LinkExternalIdentity(authToken string, provider string, request *ExternalLoginCallbackRequest) (*ExternalIdentity, error) {
+	return client.LinkExternalIdentityContext(context.Background(), authToken, provider, request)
+}
+
+// LinkExternalIdentityContext method to call the current user link identity endpoint using the provided context
+func (client *UserAuthClient) LinkExternalIdentityContext(ctx context.Context, authToken string, provider string, request *ExternalLoginCallbackRequest) (*ExternalIdentity, error) {
+	urlSuffix, err := substitutePathParams(CurrentUserIdentityUrlSuffix, pathParam{":provider", provider})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var identity ExternalIdentity
+
+	err = client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      urlSuffix,
+		authToken:      authToken,
+		body:           request,
+		expectedStatus: http.StatusCreated,
+		response:       &identity,
+		operation:      "link external identity",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// UnlinkExternalIdentity method to call the current user unlink identity endpoint
+// The last identity of a user without a password cannot be unlinked, as they would be unable to log in.
+// ValidationErrors are returned without calling the service if the provider is empty or a dot segment.
+func (client *UserAuthClient) UnlinkExternalIdentity(authToken string, provider string) error {
+	return client.UnlinkExternalIdentityContext(context.Background(), authToken, provider)
+}
+
+// UnlinkExternalIdentityContext method to call the current user unlink identity endpoint using the provided context
+func (client *UserAuthClient) UnlinkExternalIdentityContext(ctx context.Context, authToken string, provider string) error {
+	urlSuffix, err := substitutePathParams(CurrentUserIdentityUrlSuffix, pathParam{":provider", provider})
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      urlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusNoContent,
+		operation:      "unlink external identity",
+	})
+}
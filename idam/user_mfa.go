@@ -0,0 +1,101 @@
+package idam
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	UserMFAVerificationUrlSuffix         = "/api/idam/user-account/mfa/verify"
+	CurrentUserTOTPUrlSuffix             = "/api/idam/user-account/me/mfa/totp"
+	CurrentUserTOTPConfirmationUrlSuffix = "/api/idam/user-account/me/mfa/totp/confirm"
+	CurrentUserTOTPDisableUrlSuffix      = "/api/idam/user-account/me/mfa/totp/disable"
+)
+
+// VerifyMFA method to call the MFA verification endpoint
+// The code completes the MFAChallenge returned by Login, issuing the tokens the login would have returned.
+func (client *UserAuthClient) VerifyMFA(challengeId string, code string) (*UserLoginResponse, error) {
+	return client.VerifyMFAContext(context.Background(), challengeId, code)
+}
+
+// VerifyMFAContext method to call the MFA verification endpoint using the provided context
+func (client *UserAuthClient) VerifyMFAContext(ctx context.Context, challengeId string, code string) (*UserLoginResponse, error) {
+	var loginResponse UserLoginResponse
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      UserMFAVerificationUrlSuffix,
+		body:           &MFAVerificationRequest{ChallengeId: challengeId, Code: code},
+		expectedStatus: http.StatusOK,
+		response:       &loginResponse,
+		operation:      "verify mfa",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &loginResponse, nil
+}
+
+// EnrollTOTP method to call the current user TOTP enrollment endpoint
+// The returned secret must be confirmed with ConfirmTOTPEnrollment before logins require it.
+func (client *UserAuthClient) EnrollTOTP(authToken string) (*TOTPEnrollment, error) {
+	return client.EnrollTOTPContext(context.Background(), authToken)
+}
+
+// EnrollTOTPContext method to call the current user TOTP enrollment endpoint using the provided context
+func (client *UserAuthClient) EnrollTOTPContext(ctx context.Context, authToken string) (*TOTPEnrollment, error) {
+	var enrollment TOTPEnrollment
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPost,
+		urlSuffix:      CurrentUserTOTPUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusCreated,
+		response:       &enrollment,
+		operation:      "enroll totp",
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrollment, nil
+}
+
+// ConfirmTOTPEnrollment method to call the current user TOTP enrollment confirmation endpoint
+// The code must be generated from the secret returned by EnrollTOTP, proving the authenticator app was set up.
+func (client *UserAuthClient) ConfirmTOTPEnrollment(authToken string, code string) error {
+	return client.ConfirmTOTPEnrollmentContext(context.Background(), authToken, code)
+}
+
+// ConfirmTOTPEnrollmentContext method to call the current user TOTP enrollment confirmation endpoint using the provided context
+func (client *UserAuthClient) ConfirmTOTPEnrollmentContext(ctx context.Context, authToken string, code string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      CurrentUserTOTPConfirmationUrlSuffix,
+		authToken:      authToken,
+		body:           &MFACodeRequest{Code: code},
+		expectedStatus: http.StatusNoContent,
+		operation:      "confirm totp enrollment",
+	})
+}
+
+// DisableTOTP method to call the current user TOTP disable endpoint
+// A current TOTP code or an unused recovery code is required.
+func (client *UserAuthClient) DisableTOTP(authToken string, code string) error {
+	return client.DisableTOTPContext(context.Background(), authToken, code)
+}
+
+// DisableTOTPContext method to call the current user TOTP disable endpoint using the provided context
+func (client *UserAuthClient) DisableTOTPContext(ctx context.Context, authToken string, code string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodPut,
+		urlSuffix:      CurrentUserTOTPDisableUrlSuffix,
+		authToken:      authToken,
+		body:           &MFACodeRequest{Code: code},
+		expectedStatus: http.StatusNoContent,
+		operation:      "disable totp",
+	})
+}
@@ -0,0 +1,82 @@
+package idam
+
+import (
+	"context"
+	"net/http"
+)
+
+const (
+	CurrentUserSessionsUrlSuffix      = "/api/idam/user-account/me/sessions"
+	CurrentUserSessionUrlSuffix       = "/api/idam/user-account/me/sessions/:sessionId"
+	CurrentUserOtherSessionsUrlSuffix = "/api/idam/user-account/me/other-sessions"
+)
+
+// ListSessions method to call the current user sessions endpoint
+// Every session the user is logged in with is returned, the one the authToken belongs to is marked Current.
+func (client *UserAuthClient) ListSessions(authToken string) ([]Session, error) {
+	return client.ListSessionsContext(context.Background(), authToken)
+}
+
+// ListSessionsContext method to call the current user sessions endpoint using the provided context
+func (client *UserAuthClient) ListSessionsContext(ctx context.Context, authToken string) ([]Session, error) {
+	var sessions []Session
+
+	err := client.executor.execute(ctx, apiRequest{
+		method:         http.MethodGet,
+		urlSuffix:      CurrentUserSessionsUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusOK,
+		response:       &sessions,
+		operation:      "list sessions",
+		retryable:      true,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession method to call the current user revoke session endpoint
+// The session's refresh token stops working immediately. Its access token is rejected by the IDAM service,
+// but a TokenVerifier checking tokens locally accepts it until it expires.
+// A SessionNotFound error is returned if the session does not belong to the user,
+// and ValidationErrors without calling the service if the sessionId is empty or a dot segment.
+func (client *UserAuthClient) RevokeSession(authToken string, sessionId string) error {
+	return client.RevokeSessionContext(context.Background(), authToken, sessionId)
+}
+
+// RevokeSessionContext method to call the current user revoke session endpoint using the provided context
+func (client *UserAuthClient) RevokeSessionContext(ctx context.Context, authToken string, sessionId string) error {
+	urlSuffix, err := substitutePathParams(CurrentUserSessionUrlSuffix, pathParam{":sessionId", sessionId})
+
+	if err != nil {
+		return err
+	}
+
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      urlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusNoContent,
+		operation:      "revoke session",
+	})
+}
+
+// RevokeAllOtherSessions method to call the current user revoke other sessions endpoint
+// Every session except the one the authToken belongs to is revoked, signing the user out of their other devices.
+func (client *UserAuthClient) RevokeAllOtherSessions(authToken string) error {
+	return client.RevokeAllOtherSessionsContext(context.Background(), authToken)
+}
+
+// RevokeAllOtherSessionsContext method to call the current user revoke other sessions endpoint using the provided context
+func (client *UserAuthClient) RevokeAllOtherSessionsContext(ctx context.Context, authToken string) error {
+	return client.executor.execute(ctx, apiRequest{
+		method:         http.MethodDelete,
+		urlSuffix:      CurrentUserOtherSessionsUrlSuffix,
+		authToken:      authToken,
+		expectedStatus: http.StatusNoContent,
+		operation:      "revoke other sessions",
+	})
+}
@@ -0,0 +1,58 @@
+package idam
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRevokeSessionRejectsInvalidSessionIds(t *testing.T) {
+	for _, sessionId := range []string{"", ".", ".."} {
+		t.Run(sessionId, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusNoContent, ""))
+			client := newTestClient(t, server)
+
+			var validationErrors ValidationErrors
+
+			if err := client.RevokeSession("token", sessionId); !errors.As(err, &validationErrors) || validationErrors[0].Field != "sessionId" {
+				t.Errorf("error = %v, want a validation error of the sessionId field", err)
+			}
+
+			if got := server.requestCount(); got != 0 {
+				t.Errorf("requests = %d, want none", got)
+			}
+		})
+	}
+}
+
+func TestSessionRevocationRoutes(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(client *UserAuthClient) error
+		wantPath string
+	}{
+		{"RevokeSession", func(client *UserAuthClient) error {
+			return client.RevokeSession("token", "session-1")
+		}, "/api/idam/user-account/me/sessions/session-1"},
+		{"RevokeSession of a session with the id others", func(client *UserAuthClient) error {
+			return client.RevokeSession("token", "others")
+		}, "/api/idam/user-account/me/sessions/others"},
+		{"RevokeAllOtherSessions", func(client *UserAuthClient) error {
+			return client.RevokeAllOtherSessions("token")
+		}, CurrentUserOtherSessionsUrlSuffix},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestServer(t, respondWith(http.StatusNoContent, ""))
+
+			if err := tt.call(newTestClient(t, server)); err != nil {
+				t.Fatal(err)
+			}
+
+			if request := server.lastRequest(t); request.method != http.MethodDelete || request.path != tt.wantPath {
+				t.Errorf("request = %s %s, want %s %s", request.method, request.path, http.MethodDelete, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package idam
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dmars8047/strval"
+)
+
+// validationRule is a strval option identified by the rule it checks
+type validationRule struct {
+	name   string
+	option strval.StringValidationOption
+	params map[string]string
+}
+
+// rule names the strval option
+func rule(name string, option strval.StringValidationOption) validationRule {
+	return validationRule{name: name, option: option}
+}
+
+// with adds a param to the rule's errors, so localized messages can include the value the rule was checked against
+func (rule validationRule) with(key string, value string) validationRule {
+	params := map[string]string{}
+
+	for k, v := range rule.params {
+		params[k] = v
+	}
+
+	params[key] = value
+	rule.params = params
+
+	return rule
+}
+
+// validateField validates the field's value against every rule, returning an error for each rule that fails
+func validateField(value string, field string, rules ...validationRule) ValidationErrors {
+	var errs ValidationErrors
+
+	for _, rule := range rules {
+		if err := rule.option(value, field); err != nil {
+			errs = append(errs, ValidationError{Field: field, Rule: rule.name, Message: err.Error(), Params: rule.params})
+		}
+	}
+
+	return errs
+}
+
+// mustBeAbsoluteURL validates that the string is an absolute url without a fragment
+func mustBeAbsoluteURL() strval.StringValidationOption {
+	return func(str, strName string) error {
+		parsed, err := url.Parse(str)
+
+		if err != nil || !parsed.IsAbs() || parsed.Host == "" || parsed.Fragment != "" {
+			return fmt.Errorf("%s must be an absolute url without a fragment", strName)
+		}
+
+		return nil
+	}
+}
+
+// mustBeOneOf validates that the string is one of the values
+func mustBeOneOf(values ...string) strval.StringValidationOption {
+	return func(str, strName string) error {
+		for _, value := range values {
+			if str == value {
+				return nil
+			}
+		}
+
+		return fmt.Errorf("%s must be one of: %s", strName, strings.Join(values, ", "))
+	}
+}
+
+// mustNotBeOneOf validates that the string is none of the values
+func mustNotBeOneOf(values ...string) strval.StringValidationOption {
+	return func(str, strName string) error {
+		for _, value := range values {
+			if str == value {
+				return fmt.Errorf("%s must not be one of: %s", strName, strings.Join(values, ", "))
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,101 @@
+package idam
+
+import "strings"
+
+// The rules reported in ValidationError.Rule
+const (
+	ValidationRuleRequired             = "required"
+	ValidationRuleRequireOneOf         = "require_one_of"
+	ValidationRuleMinLength            = "min_length"
+	ValidationRuleMaxLength            = "max_length"
+	ValidationRuleAlphanumeric         = "alphanumeric"
+	ValidationRuleEmail                = "email"
+	ValidationRuleUppercase            = "uppercase"
+	ValidationRuleLowercase            = "lowercase"
+	ValidationRuleNumber               = "number"
+	ValidationRuleSpecialCharacter     = "special_character"
+	ValidationRuleDisallowedCharacters = "disallowed_characters"
+	ValidationRulePrintable            = "printable"
+	ValidationRuleASCII                = "ascii"
+	ValidationRuleStrength             = "strength"
+	ValidationRuleBreached             = "breached"
+	ValidationRuleBreachCheckFailed    = "breach_check_failed"
+	ValidationRuleNotSameAs            = "not_same_as"
+	ValidationRuleAbsoluteURL          = "absolute_url"
+	ValidationRuleNonNegative          = "non_negative"
+	ValidationRuleNotShorterThan       = "not_shorter_than"
+	ValidationRuleOneOf                = "one_of"
+	ValidationRuleNotOneOf             = "not_one_of"
+)
+
+// ValidationError describes a single rule a request field failed
+type ValidationError struct {
+	// The JSON name of the field, empty if the error applies to the request as a whole
+	Field string `json:"field"`
+	// The rule the field failed, one of the ValidationRule constants
+	Rule string `json:"rule"`
+	// The human readable validation message
+	Message string `json:"message"`
+	// The values substituted into the message, e.g. "length" for the min_length rule
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Error returns the validation message
+func (err ValidationError) Error() string {
+	return err.Message
+}
+
+// ValidationErrors are the rules a request failed, in the order they were checked.
+// An empty ValidationErrors means the request is valid.
+type ValidationErrors []ValidationError
+
+// Error returns the validation messages joined together
+func (errs ValidationErrors) Error() string {
+	return strings.Join(errs.Messages(), "; ")
+}
+
+// Messages returns the validation messages, nil if there are none
+func (errs ValidationErrors) Messages() []string {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(errs))
+
+	for i, err := range errs {
+		messages[i] = err.Message
+	}
+
+	return messages
+}
+
+// Field returns the errors for the named field
+func (errs ValidationErrors) Field(field string) ValidationErrors {
+	var fieldErrs ValidationErrors
+
+	for _, err := range errs {
+		if err.Field == field {
+			fieldErrs = append(fieldErrs, err)
+		}
+	}
+
+	return fieldErrs
+}
+
+// ErrorResponse converts the errors into the RequestValidationFailure ErrorResponse the IDAM service responds with.
+// The messages are returned as the details and the errors themselves as the validation errors.
+func (errs ValidationErrors) ErrorResponse() *ErrorResponse {
+	errorResponse := NewDetailedErrorResponse(RequestValidationFailure, RequestValidationFailureMessage, errs.Messages()...)
+	errorResponse.ValidationErrors = errs
+
+	return errorResponse
+}
+
+// result converts the errors into the (valid, messages) pair returned by the request Validate methods
+func (errs ValidationErrors) result() (valid bool, errors []string) {
+	if len(errs) > 0 {
+		return false, errs.Messages()
+	}
+
+	return true, nil
+}
@@ -0,0 +1,103 @@
+package idam
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestValidateFieldsReportsEachField(t *testing.T) {
+	request := &UserRegistrationRequest{Username: "al", Email: "not-an-email", Password: "Str0ng!Passw0rd"}
+
+	validationErrors := request.ValidateFields()
+
+	usernameErrors := validationErrors.Field("username")
+
+	if len(usernameErrors) != 1 || usernameErrors[0].Rule != ValidationRuleMinLength || usernameErrors[0].Params["length"] != "3" {
+		t.Errorf("username errors = %+v, want the min_length rule with its length", usernameErrors)
+	}
+
+	emailErrors := validationErrors.Field("email")
+
+	if len(emailErrors) != 1 || emailErrors[0].Rule != ValidationRuleEmail {
+		t.Errorf("email errors = %+v, want the email rule", emailErrors)
+	}
+
+	if passwordErrors := validationErrors.Field("password"); passwordErrors != nil {
+		t.Errorf("password errors = %+v, want none", passwordErrors)
+	}
+
+	valid, messages := request.Validate()
+
+	if valid || !slices.Equal(messages, validationErrors.Messages()) {
+		t.Errorf("Validate = %v, %v, want the messages of ValidateFields", valid, messages)
+	}
+}
+
+func TestValidateFieldsOfAValidRequest(t *testing.T) {
+	request := &UserRegistrationRequest{Username: "alice", Email: "alice@example.com", Password: "Str0ng!Passw0rd"}
+
+	if validationErrors := request.ValidateFields(); len(validationErrors) != 0 {
+		t.Errorf("validation errors = %v, want none", validationErrors)
+	}
+
+	if valid, messages := request.Validate(); !valid || messages != nil {
+		t.Errorf("Validate = %v, %v, want valid with no messages", valid, messages)
+	}
+}
+
+func TestValidationErrorsErrorResponse(t *testing.T) {
+	validationErrors := ValidationErrors{
+		{Field: "username", Rule: ValidationRuleRequired, Message: "username is required"},
+		{Field: "redirect_uri", Rule: ValidationRuleAbsoluteURL, Message: "redirect_uri must be an absolute url"},
+	}
+
+	errorResponse := validationErrors.ErrorResponse()
+
+	if !errors.Is(errorResponse, ErrRequestValidationFailure) {
+		t.Errorf("error response = %v, want %v", errorResponse, ErrRequestValidationFailure)
+	}
+
+	if want := []string{"username is required", "redirect_uri must be an absolute url"}; !slices.Equal(errorResponse.Details, want) {
+		t.Errorf("details = %v, want %v", errorResponse.Details, want)
+	}
+
+	if want := "username is required; redirect_uri must be an absolute url"; validationErrors.Error() != want {
+		t.Errorf("Error = %q, want %q", validationErrors.Error(), want)
+	}
+
+	if ValidationErrors(nil).Messages() != nil {
+		t.Error("Messages of no errors is not nil")
+	}
+}
+
+func TestValidationErrorsJSONRoundTrip(t *testing.T) {
+	validationErrors := ValidationErrors{
+		{Field: "username", Rule: ValidationRuleMaxLength, Message: "username must be at most 20 characters", Params: map[string]string{"length": "20"}},
+		{Rule: ValidationRuleRequireOneOf, Message: "a username or email is required"},
+	}
+
+	body, err := json.Marshal(validationErrors.ErrorResponse())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The validation errors of a failed request are decoded from the response of the IDAM service
+	server := newTestServer(t, respondWith(http.StatusBadRequest, string(body)))
+
+	_, err = newTestClient(t, server).UpdateUsername("token", &UserUsernameUpdateRequest{Username: "bob"})
+
+	errorResponse, ok := AsErrorResponse(err)
+
+	if !ok {
+		t.Fatalf("error = %v, want an ErrorResponse", err)
+	}
+
+	if !reflect.DeepEqual(errorResponse.ValidationErrors, validationErrors) {
+		t.Errorf("validation errors = %+v, want %+v", errorResponse.ValidationErrors, validationErrors)
+	}
+}
@@ -4,3 +4,18 @@ type UserAccountVerificationRequest struct {
 	UserId            string `json:"user_id"`
 	VerificationToken string `json:"verification_token"`
 }
+
+// UserVerificationResendRequest is the request object for the resend verification endpoint
+type UserVerificationResendRequest struct {
+	Email string `json:"email"`
+}
+
+// Validate validates the resend verification request
+func (request *UserVerificationResendRequest) Validate() (valid bool, errors []string) {
+	return request.ValidateFields().result()
+}
+
+// ValidateFields validates the resend verification request, returning the errors of each field
+func (request *UserVerificationResendRequest) ValidateFields() ValidationErrors {
+	return validateEmail(request.Email)
+}
@@ -0,0 +1,53 @@
+package idam_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dmars8047/idamlib/idam"
+	"github.com/dmars8047/idamlib/idam/idamtest"
+)
+
+func TestResendVerification(t *testing.T) {
+	server := newFakeServer(t)
+	user := server.CreateUser("alice", "alice@example.com", testPassword, false)
+	client := server.UserAuthClient()
+
+	previousToken, _ := server.VerificationToken("alice@example.com")
+
+	if err := client.ResendVerification(idamtest.DefaultApplicationId, "alice@example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	token, ok := server.VerificationToken("alice@example.com")
+
+	if !ok || token == previousToken {
+		t.Fatal("no new verification token was issued")
+	}
+
+	err := client.VerifyAccount(idamtest.DefaultApplicationId, &idam.UserAccountVerificationRequest{UserId: user.Id, VerificationToken: previousToken})
+
+	if !errors.Is(err, idam.ErrInvalidUserVerficationToken) {
+		t.Errorf("VerifyAccount with the replaced token error = %v, want %v", err, idam.ErrInvalidUserVerficationToken)
+	}
+
+	if err := client.VerifyAccount(idamtest.DefaultApplicationId, &idam.UserAccountVerificationRequest{UserId: user.Id, VerificationToken: token}); err != nil {
+		t.Errorf("VerifyAccount with the new token returned error %v", err)
+	}
+}
+
+func TestResendVerificationDoesNotRevealRegisteredEmails(t *testing.T) {
+	server := newFakeServer(t)
+	server.CreateUser("alice", "alice@example.com", testPassword, true)
+	client := server.UserAuthClient()
+
+	for _, email := range []string{"alice@example.com", "bob@example.com"} {
+		if err := client.ResendVerification(idamtest.DefaultApplicationId, email); err != nil {
+			t.Errorf("ResendVerification(%q) returned error %v", email, err)
+		}
+	}
+
+	if err := client.ResendVerification(idamtest.DefaultApplicationId, "not-an-email"); !errors.Is(err, idam.ErrRequestValidationFailure) {
+		t.Errorf("ResendVerification of an invalid email error = %v, want %v", err, idam.ErrRequestValidationFailure)
+	}
+}